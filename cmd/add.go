@@ -1,10 +1,12 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"moodgit/internal"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var addCmd = &cobra.Command{
@@ -14,26 +16,83 @@ var addCmd = &cobra.Command{
 
 this command allows you to record your current mood with various details:
 - intensity: A scale from 0-10 indicating how strong the mood is
-- mood: The type of mood (happy, sad, angry, anxious, excited, calm, stressed, tired, neutral)
+- mood: The type of mood (happy, sad, angry, anxious, excited, calm, stressed, tired,
+  neutral or one you defined with moodgit mood add)
 - message: An optional description of your current state or what triggered the mood
 - tags: Comma-separated tags to categorize or group related entries
-- amend: Modify the last mood entry instead of creating a new one
+- date: When the mood happened, for logging it after the fact (alias --at).
+  accepts "2026-10-16T09:30:00+02:00", "2026-10-16 09:30", "2026-10-16",
+  relative forms like "-3h", "2 days ago" or "-1mo" and day words with an
+  optional time like "yesterday 9am" or "monday 14:30". future dates are
+  rejected
+- amend: Modify the last mood entry instead of creating a new one,
+  only the fields you pass are changed and its time is kept, so it
+  can't be combined with --date
+- edit: Write the message in $VISUAL or $EDITOR, like git commit without
+  -m. the mood, intensity and tags can be set in the header of the file,
+  lines starting with # are ignored and an empty message aborts
+
+run without --intensity, --mood, --message and --tags in a terminal, add
+opens a form to pick the mood and intensity, write the message and enter
+tags with completion from the tags you already use. --date still applies.
+
+the added or amended entry is printed as a log line, or as json with
+--json and --ndjson.
 
 examples:
+  moodgit add                  # fill in the entry in a form
   moodgit add -i 8 -o happy -m "got a promotion at work!" -t work,achievement
   moodgit add -i 3 -o sad -m "feeling down today"
   moodgit add -i 7 -o excited -t weekend,vacation
-  moodgit add -a -i 9 -m "actually feeling even better!"`,
+  moodgit add -i 4 -o tired --at "yesterday 9am" -m "forgot to log this"
+  moodgit add -a -i 9 -m "actually feeling even better!"
+  moodgit add -e -o calm -i 6  # write the message in your editor
+  moodgit add -i 6 -o calm --json     # print the created entry with its id`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := internal.InitDB(); err != nil {
 			return fmt.Errorf("%w", err)
 		}
 
+		amend, _ := cmd.Flags().GetBool("amend")
+
+		output, err := outputFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		compose, _ := cmd.Flags().GetBool("edit")
+		if compose && amend {
+			return fmt.Errorf("--edit can't be combined with --amend, use moodgit edit to rewrite an entry in your editor")
+		}
+
+		if amend && cmd.Flags().Changed("date") {
+			return fmt.Errorf("--date can't be combined with --amend, an amend keeps the time of the last entry")
+		}
+
+		if amend {
+			patch := patchFromFlags(cmd)
+			if patch.IsEmpty() {
+				return fmt.Errorf("nothing to amend, pass at least one of --intensity, --mood, --message or --tags")
+			}
+
+			amended, err := internal.AmendLastEntry(patch)
+			if err != nil {
+				return fmt.Errorf("failed to amend last mood entry: %w", err)
+			}
+
+			if output != outputText {
+				return printJSON(output, amended)
+			}
+
+			fmt.Println(amended.String())
+			return nil
+		}
+
 		intensity, _ := cmd.Flags().GetInt8("intensity")
 		mood, _ := cmd.Flags().GetString("mood")
 		message, _ := cmd.Flags().GetString("message")
 		tags, _ := cmd.Flags().GetStringSlice("tags")
-		amend, _ := cmd.Flags().GetBool("amend")
+		date, _ := cmd.Flags().GetString("date")
 
 		entry := internal.Entry{
 			Intensity: intensity,
@@ -42,20 +101,71 @@ examples:
 			Tags:      tags,
 		}
 
-		if amend {
-			if err := internal.AmendLastEntry(entry); err != nil {
-				return fmt.Errorf("failed to amend last mood entry: %w", err)
+		if date != "" {
+			createdAt, err := internal.ParsePastTime(date, internal.Now())
+			if err != nil {
+				return fmt.Errorf("invalid --date: %w", err)
+			}
+			entry.CreatedAt = createdAt
+		}
+
+		var added internal.Entry
+		if compose {
+			patch, err := internal.ComposeEntry(patchFromFlags(cmd))
+			if err != nil {
+				return fmt.Errorf("%w", err)
+			}
+			if patch.Mood == nil || patch.Intensity == nil {
+				return fmt.Errorf("mood and intensity are required, set them in the header or with --mood and --intensity")
+			}
+
+			entry.Mood, entry.Intensity, entry.Message = *patch.Mood, *patch.Intensity, *patch.Message
+			if patch.Tags != nil {
+				entry.Tags = *patch.Tags
+			}
+
+			added, err = internal.AddEntry(entry)
+			if err != nil {
+				return fmt.Errorf("failed to add mood entry: %w", err)
+			}
+		} else if !anyFlagChanged(cmd, "intensity", "mood", "message", "tags") && internal.IsInteractive() {
+			added, err = internal.StartInteractiveAdd(entry)
+			if errors.Is(err, internal.ErrAddAborted) {
+				fmt.Println("aborted")
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to add mood entry: %w", err)
 			}
 		} else {
-			if err := internal.AddEntry(entry); err != nil {
+			if !cmd.Flags().Changed("intensity") || !cmd.Flags().Changed("mood") {
+				return fmt.Errorf(`required flags "intensity" and "mood" not set`)
+			}
+
+			added, err = internal.AddEntry(entry)
+			if err != nil {
 				return fmt.Errorf("failed to add mood entry: %w", err)
 			}
 		}
 
+		if output != outputText {
+			return printJSON(output, added)
+		}
+
+		fmt.Println(added.String())
 		return nil
 	},
 }
 
+func anyFlagChanged(cmd *cobra.Command, names ...string) bool {
+	for _, name := range names {
+		if cmd.Flags().Changed(name) {
+			return true
+		}
+	}
+	return false
+}
+
 func init() {
 	rootCmd.AddCommand(addCmd)
 
@@ -64,7 +174,16 @@ func init() {
 	addCmd.Flags().StringP("mood", "o", "", "select your mood")
 	addCmd.Flags().StringP("message", "m", "", "describe your mood")
 	addCmd.Flags().StringSliceP("tags", "t", []string{}, "add tags to your entry (comma separated)")
+	addCmd.Flags().BoolP("edit", "e", false, "write the entry in $VISUAL or $EDITOR")
+	addCmd.RegisterFlagCompletionFunc("mood", completeMoods)
+	addCmd.RegisterFlagCompletionFunc("tags", completeTags)
+	addJSONFlags(addCmd)
+	addCmd.Flags().StringP("date", "d", "", "when the mood happened, e.g. \"yesterday 9am\" or \"-3h\" (default now)")
 
-	addCmd.MarkFlagRequired("intensity")
-	addCmd.MarkFlagRequired("mood")
+	addCmd.Flags().SetNormalizeFunc(func(f *pflag.FlagSet, name string) pflag.NormalizedName {
+		if name == "at" {
+			name = "date"
+		}
+		return pflag.NormalizedName(name)
+	})
 }
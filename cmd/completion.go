@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"fmt"
+	"moodgit/internal"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// how many recent entries and revisions are offered when completing ids
+const completionLimit = 50
+
+var completionCmd = &cobra.Command{
+	Use:   "completion bash|zsh|fish|powershell",
+	Short: "generate shell completion scripts",
+	Long: `generate a completion script for your shell.
+
+besides commands and flags, moods, tags (most used first), entry ids
+and revisions are completed from your journal.
+
+bash:
+  source <(moodgit completion bash)
+  # or once, for every new shell:
+  moodgit completion bash > /etc/bash_completion.d/moodgit
+
+zsh:
+  moodgit completion zsh > "${fpath[1]}/_moodgit"
+  # completion has to be enabled: autoload -U compinit; compinit
+
+fish:
+  moodgit completion fish > ~/.config/fish/completions/moodgit.fish
+
+powershell:
+  moodgit completion powershell | Out-String | Invoke-Expression`,
+	Args:                  cobra.ExactArgs(1),
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+	DisableFlagsInUseLine: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch args[0] {
+		case "bash":
+			return rootCmd.GenBashCompletionV2(os.Stdout, true)
+		case "zsh":
+			return rootCmd.GenZshCompletion(os.Stdout)
+		case "fish":
+			return rootCmd.GenFishCompletion(os.Stdout, true)
+		case "powershell":
+			return rootCmd.GenPowerShellCompletionWithDesc(os.Stdout)
+		}
+
+		return fmt.Errorf("unsupported shell %q, expected bash, zsh, fish or powershell", args[0])
+	},
+}
+
+// openForCompletion opens the database of the repository given on the
+// command line read-only.
+func openForCompletion(cmd *cobra.Command) error {
+	if err := applyGlobalFlags(cmd); err != nil {
+		return err
+	}
+	return internal.OpenDBReadOnly()
+}
+
+// completeMoods completes a mood flag, including the comma separated values
+// of slice flags.
+func completeMoods(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if err := openForCompletion(cmd); err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var moods []cobra.Completion
+	for _, def := range internal.Moods() {
+		moods = append(moods, cobra.CompletionWithDesc(def.Name, def.Label()))
+	}
+
+	return completeList(moods, toComplete), cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// completeTags completes tags already in use, the most used first.
+func completeTags(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if err := openForCompletion(cmd); err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	counts, err := internal.TagCounts()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var tags []cobra.Completion
+	for _, tc := range counts {
+		tags = append(tags, cobra.CompletionWithDesc(tc.Tag, fmt.Sprintf("%d entries", tc.Count)))
+	}
+
+	return completeList(tags, toComplete), cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// completeList completes the last item of a comma separated list, skipping
+// the values already given.
+func completeList(values []cobra.Completion, toComplete string) []cobra.Completion {
+	done, last := "", toComplete
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		done, last = toComplete[:i+1], toComplete[i+1:]
+	}
+	given := strings.Split(done, ",")
+
+	var completions []cobra.Completion
+	for _, value := range values {
+		name, _, _ := strings.Cut(value, "\t")
+		if strings.HasPrefix(name, last) && !slices.Contains(given, name) {
+			completions = append(completions, done+value)
+		}
+	}
+
+	return completions
+}
+
+// completeEntryRefs completes the ids of the most recent entries. single
+// entry commands only complete the first argument.
+func completeEntryRefs(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 && cmd.Args != nil && cmd.Args(cmd, append(args, "")) != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	if err := openForCompletion(cmd); err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	entries, err := internal.QueryEntries(internal.HistoryFilter{Limit: completionLimit})
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var refs []cobra.Completion
+	for _, entry := range entries {
+		id := strconv.Itoa(entry.ID)
+		if !strings.HasPrefix(id, toComplete) || slices.Contains(args, id) {
+			continue
+		}
+
+		desc := fmt.Sprintf("%s %s %02d/10", entry.CreatedAt.In(internal.Location()).Format("2006/01/02 15:04"), entry.Mood, entry.Intensity)
+		if subject := entry.Subject(); subject != "" {
+			desc += " " + subject
+		}
+		refs = append(refs, cobra.CompletionWithDesc(id, desc))
+	}
+
+	return refs, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// completeRevisions completes the most recent reflog revisions as rN.
+func completeRevisions(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	if err := openForCompletion(cmd); err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	revisions, err := internal.ListRevisions(0, completionLimit)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []cobra.Completion
+	for _, rev := range revisions {
+		ref := fmt.Sprintf("r%d", rev.ID)
+		if strings.HasPrefix(ref, toComplete) {
+			completions = append(completions, cobra.CompletionWithDesc(ref, fmt.Sprintf("%s of entry %d", rev.Operation, rev.EntryID)))
+		}
+	}
+
+	return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+func init() {
+	rootCmd.AddCommand(completionCmd)
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
+}
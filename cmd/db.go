@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"moodgit/internal"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "manage the moodgit database",
+	Long: `manage the moodgit database.
+
+moodgit keeps its schema under version control: every change to the
+database layout ships as a numbered migration that is applied once,
+in order, inside a transaction. pending migrations are applied
+automatically whenever moodgit opens the database.`,
+}
+
+var dbMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "apply pending schema migrations",
+	Long: `apply all pending schema migrations to your moodgit database.
+
+pending migrations are applied in order inside a single transaction, so
+a failing migration leaves the database as it was.
+moodgit refuses to open a database written by a newer version of itself.
+--status only reads the database, it never creates or changes it.
+
+examples:
+  moodgit db migrate           # apply pending migrations
+  moodgit db migrate --status  # list applied and pending migrations`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		status, _ := cmd.Flags().GetBool("status")
+
+		if status {
+			if err := internal.InspectDB(); err != nil {
+				return fmt.Errorf("%w", err)
+			}
+
+			states, err := internal.MigrationStatus()
+			if err != nil {
+				return fmt.Errorf("failed to read migration status: %w", err)
+			}
+
+			for _, s := range states {
+				if s.Applied {
+					fmt.Printf("%s %04d_%s (applied %s)\n", color.Green.Sprint("[x]"), s.Version, s.Name, s.AppliedAt.In(internal.Location()).Format("2006/01/02 15:04"))
+				} else {
+					fmt.Printf("%s %04d_%s (pending)\n", color.Yellow.Sprint("[ ]"), s.Version, s.Name)
+				}
+			}
+
+			return nil
+		}
+
+		if err := internal.OpenDB(); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		applied, err := internal.Migrate()
+		for _, m := range applied {
+			fmt.Printf("applied %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		if len(applied) == 0 {
+			fmt.Println("database is up to date")
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dbCmd)
+	dbCmd.AddCommand(dbMigrateCmd)
+
+	dbMigrateCmd.Flags().BoolP("status", "s", false, "show applied and pending migrations without applying them")
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"fmt"
+	"moodgit/internal"
+
+	"github.com/spf13/cobra"
+)
+
+var editCmd = &cobra.Command{
+	Use:   "edit <id|hash|HEAD~n>",
+	Short: "edit an existing mood entry",
+	Long: `edit any existing mood entry, not just the last one.
+
+only the fields you pass are changed, everything else is kept as is.
+without any of them the entry is opened in $VISUAL or $EDITOR, with the
+mood, intensity and tags in a header above the message. lines starting
+with # are ignored, an empty file aborts the edit and an unchanged one
+leaves the entry as it is.
+
+editing an entry rewrites the hashes of all entries after it, just like
+rewriting history in git.
+
+examples:
+  moodgit edit 12              # rewrite the entry in your editor
+  moodgit edit 12 -m "it was actually a great day"
+  moodgit edit HEAD~1 -i 6
+  moodgit edit 3fa9c1 -o calm -t meditation,morning
+  moodgit edit 12 -t ""        # remove all tags`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeEntryRefs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := internal.InitDB(); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		output, err := outputFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		entry, err := internal.ResolveRef(args[0])
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		patch := patchFromFlags(cmd)
+		if patch.IsEmpty() {
+			if !internal.IsInteractive() {
+				return fmt.Errorf("nothing to edit, pass at least one of --intensity, --mood, --message or --tags")
+			}
+
+			patch, err = internal.ComposeEdit(internal.EntryPatch{
+				Intensity: &entry.Intensity,
+				Mood:      &entry.Mood,
+				Message:   &entry.Message,
+				Tags:      &entry.Tags,
+			})
+			if err != nil {
+				return fmt.Errorf("%w", err)
+			}
+
+			if patch = patch.Changes(entry); patch.IsEmpty() {
+				fmt.Println("nothing changed, the entry is left as it was")
+				return nil
+			}
+		}
+
+		updated, err := internal.UpdateEntry(entry.ID, patch)
+		if err != nil {
+			return fmt.Errorf("failed to edit mood entry: %w", err)
+		}
+
+		if output != outputText {
+			return printJSON(output, updated)
+		}
+
+		fmt.Println(updated.String())
+		return nil
+	},
+}
+
+// patchFromFlags builds a partial update from the entry flags that were
+// actually passed on the command line.
+func patchFromFlags(cmd *cobra.Command) internal.EntryPatch {
+	var patch internal.EntryPatch
+	flags := cmd.Flags()
+
+	if flags.Changed("intensity") {
+		intensity, _ := flags.GetInt8("intensity")
+		patch.Intensity = &intensity
+	}
+	if flags.Changed("mood") {
+		mood, _ := flags.GetString("mood")
+		patch.Mood = &mood
+	}
+	if flags.Changed("message") {
+		message, _ := flags.GetString("message")
+		patch.Message = &message
+	}
+	if flags.Changed("tags") {
+		tags, _ := flags.GetStringSlice("tags")
+		patch.Tags = &tags
+	}
+
+	return patch
+}
+
+func init() {
+	rootCmd.AddCommand(editCmd)
+
+	editCmd.Flags().Int8P("intensity", "i", 0, "mood intensity (0-10)")
+	editCmd.Flags().StringP("mood", "o", "", "select your mood")
+	editCmd.Flags().StringP("message", "m", "", "describe your mood")
+	editCmd.Flags().StringSliceP("tags", "t", []string{}, "replace the tags of the entry (comma separated)")
+	editCmd.RegisterFlagCompletionFunc("mood", completeMoods)
+	editCmd.RegisterFlagCompletionFunc("tags", completeTags)
+	addJSONFlags(editCmd)
+}
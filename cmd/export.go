@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"moodgit/internal"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "export your journal",
+	Long: `export your mood entries to json, ndjson, csv or markdown, oldest
+first. the entries are written as they are read, so exporting a large
+journal doesn't need much memory.
+
+formats:
+  json       a list of entries in the schema described in the readme
+  ndjson     one json entry per line
+  csv        a header row and one row per entry, tags are comma separated
+  markdown   one section per day with the entries below it, ready to drop
+             into a notes vault
+
+the history filters select what gets exported, --mood has no -o here.
+without --output the export is written to stdout.
+
+examples:
+  moodgit export -f csv -o moods.csv
+  moodgit export --format markdown --since 2026-01-01 --output 2026.md
+  moodgit export --format ndjson --tag work | jq .score
+  moodgit export > backup.json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := internal.InitDB(); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		filter, err := filterFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		format, _ := cmd.Flags().GetString("format")
+		if !slices.Contains(internal.ExportFormats, format) {
+			return fmt.Errorf("invalid --format %q, expected one of %s", format, strings.Join(internal.ExportFormats, ", "))
+		}
+
+		path, _ := cmd.Flags().GetString("output")
+
+		var w io.Writer = os.Stdout
+		if path != "" && path != "-" {
+			file, err := os.Create(path)
+			if err != nil {
+				return fmt.Errorf("failed to create export file: %w", err)
+			}
+			defer file.Close()
+			w = file
+		}
+
+		count, err := internal.Export(w, format, filter)
+		if err != nil {
+			if path != "" && path != "-" {
+				os.Remove(path)
+			}
+			return fmt.Errorf("failed to export: %w", err)
+		}
+
+		if path != "" && path != "-" {
+			fmt.Printf("exported %d entries to %s\n", count, path)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	exportCmd.Flags().StringP("format", "f", internal.ExportJSON, "export format: "+strings.Join(internal.ExportFormats, ", "))
+	exportCmd.Flags().StringP("output", "o", "", "write the export to this file instead of stdout")
+	exportCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(internal.ExportFormats, cobra.ShellCompDirectiveNoFileComp))
+	addFilterFlags(exportCmd)
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"moodgit/internal"
+
+	"github.com/spf13/cobra"
+)
+
+// addFilterFlags registers the history filters shared by every command that
+// walks the history. --mood goes without -o on commands that already use it,
+// like export --output.
+func addFilterFlags(cmd *cobra.Command) {
+	moodShorthand := "o"
+	if cmd.Flags().ShorthandLookup("o") != nil {
+		moodShorthand = ""
+	}
+
+	cmd.Flags().String("since", "", "only entries at or after this date, e.g. 2026-10-01, yesterday, -2w")
+	cmd.Flags().String("until", "", "only entries before this date, a whole day like 2026-10-16 is included")
+	cmd.Flags().StringSliceP("mood", moodShorthand, []string{}, "only entries with this mood (repeatable)")
+	cmd.Flags().StringSlice("tag", []string{}, "only entries with this tag (repeatable)")
+	cmd.Flags().String("tag-mode", "any", "match entries with any or all of the given tags (any|all)")
+	cmd.Flags().Int8("min-intensity", 0, "only entries with at least this intensity")
+	cmd.Flags().Int8("max-intensity", 10, "only entries with at most this intensity")
+	cmd.Flags().String("grep", "", "only entries whose message contains this text")
+
+	cmd.RegisterFlagCompletionFunc("mood", completeMoods)
+	cmd.RegisterFlagCompletionFunc("tag", completeTags)
+}
+
+func filterFromFlags(cmd *cobra.Command) (internal.HistoryFilter, error) {
+	var f internal.HistoryFilter
+	flags := cmd.Flags()
+	now := internal.Now()
+
+	if since, _ := flags.GetString("since"); since != "" {
+		t, err := internal.ParseTime(since, now)
+		if err != nil {
+			return f, fmt.Errorf("invalid --since: %w", err)
+		}
+		f.Since = t
+	}
+
+	if until, _ := flags.GetString("until"); until != "" {
+		t, err := internal.ParseUntil(until, now)
+		if err != nil {
+			return f, fmt.Errorf("invalid --until: %w", err)
+		}
+		f.Until = t
+	}
+
+	moods, _ := flags.GetStringSlice("mood")
+	for _, name := range moods {
+		mood, err := internal.ResolveMood(name)
+		if err != nil {
+			return f, fmt.Errorf("invalid --mood: %w", err)
+		}
+		f.Moods = append(f.Moods, mood)
+	}
+
+	f.Tags, _ = flags.GetStringSlice("tag")
+
+	switch mode, _ := flags.GetString("tag-mode"); mode {
+	case "any":
+	case "all":
+		f.AllTags = true
+	default:
+		return f, fmt.Errorf("invalid --tag-mode %q, expected any or all", mode)
+	}
+
+	if flags.Changed("min-intensity") {
+		v, _ := flags.GetInt8("min-intensity")
+		if err := internal.ValidateIntensity(v); err != nil {
+			return f, fmt.Errorf("invalid --min-intensity: %w", err)
+		}
+		f.MinIntensity = &v
+	}
+
+	if flags.Changed("max-intensity") {
+		v, _ := flags.GetInt8("max-intensity")
+		if err := internal.ValidateIntensity(v); err != nil {
+			return f, fmt.Errorf("invalid --max-intensity: %w", err)
+		}
+		f.MaxIntensity = &v
+	}
+
+	f.Grep, _ = flags.GetString("grep")
+
+	return f, nil
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+	"moodgit/internal"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+)
+
+var fsckCmd = &cobra.Command{
+	Use:   "fsck",
+	Short: "verify the integrity of your mood history",
+	Long: `verify the integrity of your mood history.
+
+every entry carries a content hash computed over its intensity, mood,
+message, tags, timestamp and the hash of the entry before it, forming a
+git-like chain. fsck recomputes the whole chain and reports:
+- tampered entries, whose content no longer matches their hash
+- reordered entries, whose parent link doesn't point at the previous entry
+
+examples:
+  moodgit fsck`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := internal.InitDB(); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		checked, problems, err := internal.Fsck()
+		if err != nil {
+			return fmt.Errorf("failed to verify history: %w", err)
+		}
+
+		for _, p := range problems {
+			fmt.Printf("%s entry %d (%s): %s\n", color.Red.Sprint(p.Kind), p.Entry.ID, p.Entry.ShortHash(), p.Detail)
+		}
+
+		if len(problems) > 0 {
+			return fmt.Errorf("checked %d entries, found %d problems", checked, len(problems))
+		}
+
+		fmt.Printf("checked %d entries, history is intact\n", checked)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(fsckCmd)
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"moodgit/internal"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "plot your intensity or mood score over time",
+	Long: `plot the average intensity or mood score per day or per week as a
+chart sized to the terminal. the score runs from -10 (very negative) to
+10 (very positive) and combines the mood with its intensity.
+
+styles:
+- braille: a line chart drawn with braille dots (default)
+- block:   one bar per day or week, the newest ones if they don't fit
+- spark:   a single line sparkline without axes
+
+--by-mood draws one line per mood in its usual colour instead of the
+overall average, --ma adds a trailing moving average over that many
+days or weeks, except to a sparkline. days and weeks without entries
+are left out of the line.
+
+without --since the last 90 days, or the last 26 weeks with --by week,
+are shown. all other log filters apply too. weeks start on monday
+unless MOODGIT_WEEK_START names another day.
+
+examples:
+  moodgit graph
+  moodgit graph --by week --ma 4
+  moodgit graph --by-mood --since "3 weeks ago"
+  moodgit graph -m score --by week
+  moodgit graph -s block --tag work
+  moodgit graph -s spark`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := internal.InitDB(); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		filter, err := filterFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		bucket, _ := cmd.Flags().GetString("by")
+		metric, _ := cmd.Flags().GetString("metric")
+		byMood, _ := cmd.Flags().GetBool("by-mood")
+		window, _ := cmd.Flags().GetInt("ma")
+		height, _ := cmd.Flags().GetInt("height")
+		style, _ := cmd.Flags().GetString("style")
+
+		if window < 0 {
+			return fmt.Errorf("--ma must not be negative")
+		}
+
+		weekStart, err := internal.WeekStart()
+		if err != nil {
+			return fmt.Errorf("invalid MOODGIT_WEEK_START: %w", err)
+		}
+
+		to := internal.Now()
+		if !filter.Until.IsZero() {
+			// --until is exclusive
+			to = filter.Until.Add(-time.Second)
+		}
+
+		from := filter.Since
+		if from.IsZero() {
+			if bucket == internal.GraphWeekly {
+				from = to.AddDate(0, 0, -26*7+1)
+			} else {
+				from = to.AddDate(0, 0, -90+1)
+			}
+		}
+
+		if from.After(to) {
+			return fmt.Errorf("--since must be before --until")
+		}
+
+		graph, err := internal.GetGraph(filter, bucket, metric, weekStart, from, to)
+		if err != nil {
+			return fmt.Errorf("failed to build graph: %w", err)
+		}
+
+		chart, err := graph.Render(internal.GraphOptions{
+			Width:         internal.TerminalWidth(),
+			Height:        height,
+			ByMood:        byMood,
+			MovingAverage: window,
+			Style:         style,
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(chart)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(graphCmd)
+
+	graphCmd.Flags().String("by", internal.GraphDaily, "average per day or week")
+	graphCmd.Flags().StringP("metric", "m", internal.GraphIntensity, "what to plot: intensity or score")
+	graphCmd.Flags().Bool("by-mood", false, "draw one series per mood")
+	graphCmd.Flags().Int("ma", 0, "overlay a moving average over this many days or weeks")
+	graphCmd.Flags().Int("height", 12, "height of the chart in rows")
+	graphCmd.Flags().StringP("style", "s", internal.GraphBraille, "chart style: braille, block or spark")
+	addFilterFlags(graphCmd)
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"moodgit/internal"
+
+	"github.com/spf13/cobra"
+)
+
+var grepCmd = &cobra.Command{
+	Use:   "grep <query>",
+	Short: "search the messages of your entries",
+	Long: `search the messages of your entries with a full-text index and show
+the matching entries, best matches first, with the matching words
+highlighted.
+
+words are matched as a whole, ignoring case and accents, and an entry
+has to contain all of them. the query also supports:
+  "a good day"                 a phrase, the words next to each other
+  feel*                        a prefix, matches feel, feeling, feelings
+  work OR deadline             either word
+  tired NOT sleep              the first but not the second
+  (work OR job) AND stress     grouping with parentheses
+
+the history filters can narrow the search down further.
+
+examples:
+  moodgit grep deadline
+  moodgit grep '"long walk"'
+  moodgit grep 'run* NOT rain' --since -1mo
+  moodgit grep 'work OR job' -o anxious -l 5
+  moodgit grep therapy --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := internal.InitDB(); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		filter, err := filterFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		limit, _ := cmd.Flags().GetUint16("limit")
+		filter.Limit = int(limit)
+
+		output, err := outputFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		results, err := internal.SearchEntries(args[0], filter)
+		if err != nil {
+			return err
+		}
+
+		if output != outputText {
+			entries := make([]internal.Entry, len(results))
+			for i, result := range results {
+				entries[i] = result.Entry
+			}
+			return printJSONList(output, entries)
+		}
+
+		if len(results) == 0 {
+			fmt.Println("no matching entries")
+			return nil
+		}
+
+		for _, result := range results {
+			fmt.Println(result.String())
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(grepCmd)
+
+	grepCmd.Flags().Uint16P("limit", "l", 20, "maximum number of entries to show, 0 for all")
+	addFilterFlags(grepCmd)
+	addJSONFlags(grepCmd)
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"moodgit/internal"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// the full weeks shown before the current one
+const heatmapWeeks = 52
+
+var heatmapCmd = &cobra.Command{
+	Use:   "heatmap",
+	Short: "show a calendar heatmap of your moods",
+	Long: `show a github-style calendar heatmap of your moods.
+
+every cell is one day, every column one week. by default a cell is
+coloured by the dominant mood of that day, other metrics shade it:
+- mood:      the most frequent mood of the day, in its usual colour
+- intensity: the mean intensity of the day
+- count:     how many entries you logged that day
+- score:     how positive or negative the day was on average, valence
+             is accepted as an alias
+
+without --year the current week and the 52 full weeks before it are
+shown. the oldest weeks are dropped when the terminal is too narrow.
+weeks start on monday unless --week-start or MOODGIT_WEEK_START say otherwise.
+
+examples:
+  moodgit heatmap
+  moodgit heatmap --year 2025
+  moodgit heatmap -m intensity
+  moodgit heatmap -m score --week-start sunday`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := internal.InitDB(); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		metric, _ := cmd.Flags().GetString("metric")
+		year, _ := cmd.Flags().GetInt("year")
+
+		weekStart, err := internal.WeekStart()
+		if err != nil {
+			return fmt.Errorf("invalid MOODGIT_WEEK_START: %w", err)
+		}
+		if name, _ := cmd.Flags().GetString("week-start"); name != "" {
+			if weekStart, err = internal.ParseWeekday(name); err != nil {
+				return fmt.Errorf("invalid --week-start: %w", err)
+			}
+		}
+
+		now := internal.Now()
+		from, to := internal.StartOfWeek(now, weekStart).AddDate(0, 0, -heatmapWeeks*7), now
+		if year != 0 {
+			from = time.Date(year, time.January, 1, 0, 0, 0, 0, internal.Location())
+			to = time.Date(year, time.December, 31, 0, 0, 0, 0, internal.Location())
+		}
+
+		heatmap, err := internal.GetHeatmap(from, to, weekStart, metric)
+		if err != nil {
+			return fmt.Errorf("failed to build heatmap: %w", err)
+		}
+
+		fmt.Println(heatmap.Render(internal.TerminalWidth()))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(heatmapCmd)
+
+	heatmapCmd.Flags().StringP("metric", "m", internal.HeatmapMood, "what a cell shows: mood, intensity, count or score (alias valence)")
+	heatmapCmd.Flags().IntP("year", "y", 0, "show this calendar year instead of the last 53 weeks")
+	heatmapCmd.Flags().String("week-start", "", "first day of the week (default $MOODGIT_WEEK_START or monday)")
+}
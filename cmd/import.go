@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"moodgit/internal"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "import entries from json, ndjson or csv",
+	Long: `import mood entries from a file, e.g. an export of another journal
+or the history of another tracker. use - to read from stdin.
+
+the file can be in any format moodgit export writes except markdown. the
+format is taken from the file extension (.json, .ndjson, .jsonl, .csv) or
+the content, --format overrides it. the original timestamps are kept,
+timestamps without an offset are read in your time zone. ids and hashes
+are not imported, the entries are appended to the hash chain.
+
+csv files need a header row. columns are matched to the fields created_at,
+updated_at, tz, mood, intensity, message and tags by name, --column maps a
+field to a differently named column. tags are comma separated.
+
+exports of other mood apps are read with --from:
+  daylio     daylio's csv export, activities become tags
+  generic    rows of date, mood label and an optional note
+their mood labels, or the steps of a 1 to 5 scale, are mapped to moods and
+intensities. daylio's default labels (rad, good, meh, bad, awful), 1 to 5
+and the names of your moods are mapped out of the box, --mapping adds to
+that from a json file:
+  {"moods": {"rad": {"mood": "excited", "intensity": 9}, "meh": {"mood": "calm"}}}
+a label without an intensity is imported with intensity 5. entries with
+labels that aren't mapped are listed and the import stops, with --dry-run
+the rest is shown anyway.
+
+an entry with the same time, mood and message as an existing one is
+skipped, so importing the same file twice adds nothing. everything is
+imported in a single transaction: if one entry is invalid, nothing is
+imported. --dry-run shows what would be imported without changing
+anything.
+
+examples:
+  moodgit import backup.json
+  moodgit import moods.csv --dry-run
+  moodgit import tracker.csv --column created_at=Date --column mood=Feeling --column intensity=Level
+  moodgit import daylio_export.csv --from daylio --dry-run
+  moodgit import moods.csv --from generic --mapping labels.json
+  moodgit export --format ndjson | ssh laptop moodgit import -`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := internal.InitDB(); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		format, _ := cmd.Flags().GetString("format")
+		columns, _ := cmd.Flags().GetStringToString("column")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		from, _ := cmd.Flags().GetString("from")
+		mappingPath, _ := cmd.Flags().GetString("mapping")
+
+		if from != "" && (format != "" || len(columns) > 0) {
+			return fmt.Errorf("--from can't be combined with --format or --column")
+		}
+		if from == "" && mappingPath != "" {
+			return fmt.Errorf("--mapping only applies to --from")
+		}
+
+		if format != "" && !slices.Contains(internal.ImportFormats, format) {
+			return fmt.Errorf("invalid --format %q, expected one of %s", format, strings.Join(internal.ImportFormats, ", "))
+		}
+
+		in, err := openImport(args[0])
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+
+		var records []internal.ImportRecord
+		if from != "" {
+			mapping := internal.DefaultMoodMapping()
+			if mappingPath != "" {
+				if mapping, err = internal.LoadMoodMapping(mappingPath, mapping); err != nil {
+					return fmt.Errorf("failed to load %s: %w", mappingPath, err)
+				}
+			}
+
+			adapted, err := internal.ReadAdapterImport(in, from, mapping)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", args[0], err)
+			}
+
+			if labels := adapted.UnmappedLabels(); len(labels) > 0 {
+				fmt.Println("unmapped mood labels, add them to a --mapping file:")
+				skipped := 0
+				for _, label := range labels {
+					fmt.Printf("%6d  %s\n", adapted.Unmapped[label], label)
+					skipped += adapted.Unmapped[label]
+				}
+				if !dryRun {
+					return fmt.Errorf("%d entries have unmapped mood labels, nothing was imported", skipped)
+				}
+			}
+			records = adapted.Records
+		} else {
+			reader := bufio.NewReader(in)
+			if format == "" {
+				head, _ := reader.Peek(512)
+				format = internal.DetectImportFormat(args[0], head)
+			}
+
+			records, err = internal.ReadImport(reader, format, columns)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", args[0], err)
+			}
+		}
+
+		result, err := internal.ImportEntries(records, dryRun)
+		if err != nil {
+			return fmt.Errorf("failed to import %s: %w", args[0], err)
+		}
+
+		printImportResult(result, dryRun)
+		return nil
+	},
+}
+
+func openImport(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open import file: %w", err)
+	}
+	return file, nil
+}
+
+// printImportResult lists every entry for a dry run and only the totals
+// otherwise.
+func printImportResult(result internal.ImportResult, dryRun bool) {
+	if dryRun {
+		for _, entry := range result.Added {
+			fmt.Println("add  " + entry.String())
+		}
+		for _, record := range result.Duplicates {
+			fmt.Printf("skip %s, duplicate of an existing entry\n", record.Source)
+		}
+		fmt.Printf("would import %d entries, %d duplicates skipped\n", len(result.Added), len(result.Duplicates))
+		return
+	}
+
+	fmt.Printf("imported %d entries, %d duplicates skipped\n", len(result.Added), len(result.Duplicates))
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+
+	importCmd.Flags().StringP("format", "f", "", "import format: "+strings.Join(internal.ImportFormats, ", ")+" (default from the file)")
+	importCmd.Flags().StringToString("column", map[string]string{}, "map a field to a csv column, e.g. --column created_at=Date (repeatable)")
+	importCmd.Flags().BoolP("dry-run", "n", false, "show what would be imported without importing it")
+	importCmd.Flags().String("from", "", "read the export of another app: "+strings.Join(internal.Adapters, ", "))
+	importCmd.Flags().String("mapping", "", "json file mapping the mood labels of --from to moods")
+	importCmd.RegisterFlagCompletionFunc("from", cobra.FixedCompletions(internal.Adapters, cobra.ShellCompDirectiveNoFileComp))
+	importCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(internal.ImportFormats, cobra.ShellCompDirectiveNoFileComp))
+}
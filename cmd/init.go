@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"moodgit/internal"
 	"os"
 	"path/filepath"
 
@@ -11,21 +12,28 @@ import (
 
 var initCmd = &cobra.Command{
 	Use:   "init",
-	Short: "initialize a moodgit repository in your home directory",
-	Long: `initialize a new moodgit repository in your home directory.
+	Short: "initialize a moodgit repository",
+	Long: `initialize a new moodgit repository.
 
 this command sets up the necessary directory structure and database file
 for moodgit to store your mood entries. it creates:
-- a .moodgit directory in your home folder
+- a repository directory, ~/.moodgit by default
 - a SQLite database file (moodgit.db) to store your mood entries
 - required database schema for tracking moods
 
-the repository will be created at ~/.moodgit/ and is persistent across
-all your moodgit sessions. you only need to run this command once.
+the repository is created in the first of:
+- the directory given with --repo
+- $MOODGIT_DIR
+- $XDG_DATA_HOME/moodgit, if XDG_DATA_HOME is set and ~/.moodgit
+  doesn't exist yet
+- ~/.moodgit
+every other command looks for it in the same places. you only need to run
+this command once per repository.
 
 examples:
   moodgit init                 # initialize a new repository
-  moodgit init --force         # reinitialize and reset all data`,
+  moodgit init --force         # reinitialize and reset all data
+  moodgit --repo /mnt/vault/moodgit init`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// show ascii art
 		fmt.Println()
@@ -39,21 +47,20 @@ examples:
 
 		force, _ := cmd.Flags().GetBool("force")
 
-		// get home dir, and create a .moodgit directory
-		homeDir, err := os.UserHomeDir()
+		// find the repository directory and create it
+		dbPath, err := internal.DBPath()
 		if err != nil {
-			return fmt.Errorf("failed to get home directory: %w", err)
+			return err
 		}
 
-		repoPath := filepath.Join(homeDir, ".moodgit")
+		repoPath := filepath.Dir(dbPath)
 		if _, err := os.Stat(repoPath); os.IsNotExist(err) {
-			if err := os.Mkdir(repoPath, 0755); err != nil {
-				return fmt.Errorf("failed to create .moodgit directory: %w", err)
+			if err := os.MkdirAll(repoPath, 0755); err != nil {
+				return fmt.Errorf("failed to create repository directory: %w", err)
 			}
 		}
 
 		// create moodgit.db file
-		dbPath := filepath.Join(repoPath, "moodgit.db")
 		if _, err := os.Stat(dbPath); os.IsNotExist(err) || force {
 			file, err := os.Create(dbPath)
 			if err != nil {
@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"moodgit/internal"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -21,11 +22,36 @@ this command shows your recent mood entries with full details including:
 the entries are displayed with color-coding based on mood type and
 intensity for better visual representation.
 
+entries can be filtered the same way as with git log. all filters can be
+combined and also apply to the interactive log. dates accept the same
+forms as moodgit add --date and whole days are taken in your local time.
+
 examples:
   moodgit log                  # show last 10 entries
   moodgit log -l 20            # show last 20 entries
   moodgit log -i               # show interactive log with 10 entries per page
-  moodgit log -i -l 25         # show interactive log with 25 entries per page`,
+  moodgit log -i -l 25         # show interactive log with 25 entries per page
+  moodgit log --since 2026-10-01 --until 2026-10-15
+  moodgit log -o sad -o anxious --min-intensity 7
+  moodgit log --tag work --tag health --tag-mode all
+  moodgit log --grep deadline --reverse
+
+output formats:
+  --oneline                    short hash, mood, intensity and first line
+  --pretty=short|medium|full   multi-line formats, like git log
+  --format='%h %ad %m %i %s'   placeholders, see below
+  --format='{{.Mood}}: {{.Message}}'  a go text/template over the entry
+
+placeholders: %H/%h hash, %P/%p parent hash, %e id, %m mood, %i intensity,
+%t tags, %s subject, %b body, %B raw message, %z time zone, %ad date,
+%as short date, %aI ISO 8601 date, %at unix time, %ar relative date,
+%n newline, %% a literal %.
+
+examples:
+  moodgit log --oneline
+  moodgit log --pretty=medium -l 3
+  moodgit log --format='%as,%m,%i' -l 1000 > moods.csv
+  moodgit log --ndjson -l 1000 | jq .intensity`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := internal.InitDB(); err != nil {
 			return fmt.Errorf("%w", err)
@@ -34,21 +60,100 @@ examples:
 		limit, _ := cmd.Flags().GetUint16("limit")
 		interactive, _ := cmd.Flags().GetBool("interactive")
 
+		filter, err := filterFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		output, err := outputFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
 		if interactive {
-			if err := internal.StartInteractiveLog(int(limit)); err != nil {
+			if output != outputText {
+				return fmt.Errorf("--json and --ndjson can't be combined with --interactive")
+			}
+			if err := internal.StartInteractiveLog(int(limit), filter); err != nil {
 				return fmt.Errorf("error starting interactive log: %w", err)
 			}
-		} else {
-			return internal.GetHistory(limit)
+			return nil
+		}
+
+		filter.Limit = int(limit)
+		filter.Reverse, _ = cmd.Flags().GetBool("reverse")
+
+		format, err := formatFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		if output != outputText && format != "" {
+			return fmt.Errorf("--json and --ndjson can't be combined with --oneline, --pretty or --format")
+		}
+
+		formatter, err := internal.NewFormatter(format)
+		if err != nil {
+			return err
+		}
+
+		entries, err := internal.QueryEntries(filter)
+		if err != nil {
+			return err
+		}
+
+		if output != outputText {
+			return printJSONList(output, entries)
+		}
+
+		for _, entry := range entries {
+			line, err := formatter(&entry)
+			if err != nil {
+				return fmt.Errorf("failed to format entry %d: %w", entry.ID, err)
+			}
+			fmt.Println(line)
 		}
 
 		return nil
 	},
 }
 
+func formatFromFlags(cmd *cobra.Command) (string, error) {
+	oneline, _ := cmd.Flags().GetBool("oneline")
+	pretty, _ := cmd.Flags().GetString("pretty")
+	format, _ := cmd.Flags().GetString("format")
+
+	set := 0
+	for _, given := range []bool{oneline, pretty != "", format != ""} {
+		if given {
+			set++
+		}
+	}
+	if set > 1 {
+		return "", fmt.Errorf("--oneline, --pretty and --format are mutually exclusive")
+	}
+
+	switch {
+	case oneline:
+		return "oneline", nil
+	case format != "" && !strings.Contains(format, "{{"):
+		return "format:" + format, nil
+	case format != "":
+		return format, nil
+	}
+
+	return pretty, nil
+}
+
 func init() {
 	rootCmd.AddCommand(logCmd)
 
 	logCmd.Flags().Uint16P("limit", "l", 10, "number of entries to show (page size for interactive mode)")
 	logCmd.Flags().BoolP("interactive", "i", false, "show interactive log with pagination")
+	logCmd.Flags().Bool("reverse", false, "show the selected entries oldest first")
+	logCmd.Flags().Bool("oneline", false, "shorthand for --pretty=oneline")
+	logCmd.Flags().String("pretty", "", "output format: oneline, short, medium, full or format:<string>")
+	logCmd.Flags().String("format", "", "output entries with placeholders like '%h %ad %m %i %s' or a go template")
+	addFilterFlags(logCmd)
+	addJSONFlags(logCmd)
 }
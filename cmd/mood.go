@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"moodgit/internal"
+
+	"github.com/spf13/cobra"
+)
+
+var moodCmd = &cobra.Command{
+	Use:   "mood",
+	Short: "manage the moods you can log",
+	Long: `manage the moods you can log.
+
+moodgit starts with nine built-in moods: happy, sad, angry, anxious,
+excited, calm, stressed, tired and neutral. you can define your own,
+like grateful or overwhelmed, with a colour, an emoji and a place on
+the valence/arousal plane that feeds the mood score.`,
+}
+
+var moodListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "list all moods",
+	Long: `list all moods with their colour, valence, arousal and how many
+entries use them.
+
+examples:
+  moodgit mood list
+  moodgit mood list --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := internal.InitDB(); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		output, err := outputFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		moods, err := internal.ListMoods()
+		if err != nil {
+			return fmt.Errorf("failed to list moods: %w", err)
+		}
+
+		if output != outputText {
+			return printJSONList(output, moods)
+		}
+
+		for _, mood := range moods {
+			fmt.Println(mood.String())
+		}
+
+		return nil
+	},
+}
+
+var moodAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "define a new mood",
+	Long: `define a new mood you can log entries with. the name "all" is
+reserved for the mood filter of the interactive log.
+
+- color: a colour name (red, green, yellow, blue, magenta, cyan, white,
+  gray, lightRed, lightBlue, ...), a 256-colour index from 0 to 255 or
+  a hex value like #ff8800
+- emoji: shown next to the mood name
+- valence: how pleasant the mood is, from -1 to 1
+- arousal: how much energy the mood has, from -1 (drained) to 1 (wired)
+
+the score of an entry is the valence of its mood times its intensity.
+
+examples:
+  moodgit mood add grateful -c "#ffaf00" -e 🙏 --valence 0.9 --arousal 0.2
+  moodgit mood add overwhelmed -c 208 --valence -0.7 --arousal 0.8`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := internal.InitDB(); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		def := internal.MoodDef{Name: args[0]}
+		def.Color, _ = cmd.Flags().GetString("color")
+		def.Emoji, _ = cmd.Flags().GetString("emoji")
+		def.Valence, _ = cmd.Flags().GetFloat64("valence")
+		def.Arousal, _ = cmd.Flags().GetFloat64("arousal")
+
+		def, err := internal.AddMood(def)
+		if err != nil {
+			return fmt.Errorf("failed to add mood: %w", err)
+		}
+
+		fmt.Printf("added mood %s\n", def.Label())
+		return nil
+	},
+}
+
+var moodRmCmd = &cobra.Command{
+	Use:     "rm <name>",
+	Aliases: []string{"remove", "delete"},
+	Short:   "remove a mood",
+	Long: `remove a mood. moods that entries still use can't be removed, edit
+those entries to another mood first.
+
+examples:
+  moodgit mood rm overwhelmed`,
+	Args: cobra.ExactArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completeMoods(cmd, args, toComplete)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := internal.InitDB(); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		if err := internal.RemoveMood(args[0]); err != nil {
+			return fmt.Errorf("failed to remove mood: %w", err)
+		}
+
+		fmt.Printf("removed mood %s\n", args[0])
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(moodCmd)
+	moodCmd.AddCommand(moodListCmd, moodAddCmd, moodRmCmd)
+
+	addJSONFlags(moodListCmd)
+
+	moodAddCmd.Flags().StringP("color", "c", "white", "colour of the mood")
+	moodAddCmd.Flags().StringP("emoji", "e", "", "emoji shown next to the mood")
+	moodAddCmd.Flags().Float64("valence", 0, "how pleasant the mood is, from -1 to 1")
+	moodAddCmd.Flags().Float64("arousal", 0, "how much energy the mood has, from -1 to 1")
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	outputText   = ""
+	outputJSON   = "json"
+	outputNDJSON = "ndjson"
+)
+
+// addJSONFlags registers --json and --ndjson, see the readme for the schema.
+func addJSONFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("json", false, "print json, see the readme for the schema")
+	cmd.Flags().Bool("ndjson", false, "print newline delimited json, one object per line")
+}
+
+func outputFromFlags(cmd *cobra.Command) (string, error) {
+	asJSON, _ := cmd.Flags().GetBool("json")
+	asNDJSON, _ := cmd.Flags().GetBool("ndjson")
+
+	switch {
+	case asJSON && asNDJSON:
+		return "", fmt.Errorf("--json and --ndjson are mutually exclusive")
+	case asJSON:
+		return outputJSON, nil
+	case asNDJSON:
+		return outputNDJSON, nil
+	}
+
+	return outputText, nil
+}
+
+// printJSON writes a single value, indented for --json and on one line for
+// --ndjson.
+func printJSON(mode string, v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	if mode == outputJSON {
+		enc.SetIndent("", "  ")
+	}
+	return enc.Encode(v)
+}
+
+// printJSONList writes a json array for --json and one line per item for
+// --ndjson.
+func printJSONList[T any](mode string, items []T) error {
+	if mode == outputJSON {
+		if items == nil {
+			items = []T{}
+		}
+		return printJSON(mode, items)
+	}
+
+	for _, item := range items {
+		if err := printJSON(mode, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
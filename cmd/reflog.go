@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"moodgit/internal"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var reflogCmd = &cobra.Command{
+	Use:   "reflog [id|hash|HEAD~n]",
+	Short: "show the history of entry updates and deletions",
+	Long: `show every update, deletion and restore of your mood entries.
+
+each line is a revision: the operation, when it happened, and the state
+the entry had right before it. any revision can be brought back with
+moodgit restore.
+
+examples:
+  moodgit reflog               # show the last 20 operations
+  moodgit reflog -l 50         # show the last 50 operations
+  moodgit reflog 12            # show the operations on entry 12`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeEntryRefs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := internal.InitDB(); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		limit, _ := cmd.Flags().GetUint16("limit")
+
+		entryID := 0
+		if len(args) > 0 {
+			entry, err := internal.ResolveRef(args[0])
+			if err != nil {
+				return fmt.Errorf("%w", err)
+			}
+			entryID = entry.ID
+		}
+
+		revisions, err := internal.ListRevisions(entryID, int(limit))
+		if err != nil {
+			return fmt.Errorf("failed to read reflog: %w", err)
+		}
+
+		for _, rev := range revisions {
+			fmt.Println(rev.String())
+		}
+
+		return nil
+	},
+}
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <revision>",
+	Short: "roll an entry back to an earlier state",
+	Long: `roll an entry back to the state saved in a reflog revision.
+
+the revision can be given as "r12" or just "12". restoring a deleted
+entry brings it back under its original id. the restore itself is
+recorded in the reflog, so it can be undone as well.
+
+examples:
+  moodgit reflog
+  moodgit restore r12`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeRevisions,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := internal.InitDB(); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		// Atoi alone would take a sign
+		digits := strings.TrimPrefix(args[0], "r")
+		revisionID, err := strconv.Atoi(digits)
+		if err != nil || revisionID <= 0 || strings.HasPrefix(digits, "+") {
+			return fmt.Errorf("invalid revision %q, expected r<number> as listed by moodgit reflog", args[0])
+		}
+
+		entry, err := internal.RestoreRevision(revisionID)
+		if err != nil {
+			return fmt.Errorf("failed to restore revision: %w", err)
+		}
+
+		fmt.Println(entry.String())
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reflogCmd)
+	rootCmd.AddCommand(restoreCmd)
+
+	reflogCmd.Flags().Uint16P("limit", "l", 20, "number of operations to show")
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"moodgit/internal"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var rmCmd = &cobra.Command{
+	Use:   "rm <id|hash|HEAD~n>...",
+	Short: "delete mood entries",
+	Long: `delete one or more mood entries.
+
+the entries to delete are listed and you are asked for confirmation
+before anything is removed, use --yes to skip the prompt. either all
+entries are deleted or none of them are.
+
+examples:
+  moodgit rm 12
+  moodgit rm 12 13 3fa9c1
+  moodgit rm HEAD --yes`,
+	Aliases:           []string{"remove", "delete"},
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeEntryRefs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := internal.InitDB(); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		yes, _ := cmd.Flags().GetBool("yes")
+
+		var ids []int
+		seen := map[int]bool{}
+		for _, ref := range args {
+			entry, err := internal.ResolveRef(ref)
+			if err != nil {
+				return fmt.Errorf("%w", err)
+			}
+			if seen[entry.ID] {
+				continue
+			}
+			seen[entry.ID] = true
+
+			ids = append(ids, entry.ID)
+			fmt.Println(entry.String())
+		}
+
+		if !yes {
+			ok, err := confirm(fmt.Sprintf("delete %s?", entryCount(len(ids))))
+			if err != nil {
+				return err
+			}
+			if !ok {
+				fmt.Println("aborted")
+				return nil
+			}
+		}
+
+		if err := internal.DeleteEntries(ids); err != nil {
+			return fmt.Errorf("failed to delete mood entries: %w", err)
+		}
+
+		fmt.Printf("deleted %s\n", entryCount(len(ids)))
+		return nil
+	},
+}
+
+// entryCount says "1 entry" or "n entries".
+func entryCount(n int) string {
+	if n == 1 {
+		return "1 entry"
+	}
+	return fmt.Sprintf("%d entries", n)
+}
+
+// confirm asks a yes/no question on stdin, anything but y/yes means no. it
+// fails when stdin ends without an answer, e.g. when it isn't a terminal.
+func confirm(question string) (bool, error) {
+	fmt.Printf("%s [y/N] ", question)
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Println()
+		return false, fmt.Errorf("no answer to confirm, pass --yes to skip the question")
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+func init() {
+	rootCmd.AddCommand(rmCmd)
+
+	rmCmd.Flags().BoolP("yes", "y", false, "delete without asking for confirmation")
+}
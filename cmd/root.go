@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"moodgit/internal"
 	"os"
 
 	"github.com/gookit/color"
@@ -21,6 +22,9 @@ example workflow:
   moodgit init
   moodgit add -i 8 -o happy -m "great day at work!" -t work
   moodgit log`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return applyGlobalFlags(cmd)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		fmt.Println()
 		color.C256(201).Println("█▀▄▀█ ████▄ ████▄ ██▄     ▄▀  ▄█    ▄▄▄▄▀")
@@ -37,6 +41,18 @@ example workflow:
 	},
 }
 
+// applyGlobalFlags applies --repo and --tz. shell completion doesn't run
+// PersistentPreRunE, the completion functions call it themselves.
+func applyGlobalFlags(cmd *cobra.Command) error {
+	if repo, _ := cmd.Flags().GetString("repo"); repo != "" {
+		internal.SetRepo(repo)
+	}
+	if tz, _ := cmd.Flags().GetString("tz"); tz != "" {
+		return internal.SetTimeZone(tz)
+	}
+	return nil
+}
+
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
@@ -44,4 +60,8 @@ func Execute() {
 	}
 }
 
-func init() {}
+func init() {
+	rootCmd.PersistentFlags().String("repo", "", "use the moodgit repository in this directory (default $MOODGIT_DIR or ~/.moodgit)")
+	rootCmd.MarkPersistentFlagDirname("repo")
+	rootCmd.PersistentFlags().String("tz", "", "display times in this IANA time zone, e.g. Europe/Berlin (default local)")
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"moodgit/internal"
+
+	"github.com/spf13/cobra"
+)
+
+var showCmd = &cobra.Command{
+	Use:   "show <id|hash|HEAD~n>",
+	Short: "show a single mood entry in full",
+	Long: `show a single mood entry with all of its details.
+
+unlike log, show never truncates anything. it prints:
+- the full entry hash, id and parent hash
+- mood and an intensity bar
+- the complete, possibly multi-line message
+- all tags
+- created and amended times in your local time zone
+- the history of amendments from the reflog, if any, the newest 20
+  revisions unless --limit says otherwise
+
+an entry can be referenced by its id, by its hash (any unambiguous
+prefix of at least 4 characters), or relative to the newest entry with
+HEAD, HEAD~1, HEAD~2 and so on. without an argument HEAD is shown.
+
+examples:
+  moodgit show                 # show the newest entry
+  moodgit show 12              # show the entry with id 12
+  moodgit show 3fa9c1          # show the entry whose hash starts with 3fa9c1
+  moodgit show HEAD~2          # show the third newest entry
+  moodgit show 12 -l 100       # show up to 100 revisions of its history
+  moodgit show 12 --json       # print the entry and its history as json`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeEntryRefs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := internal.InitDB(); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		ref := "HEAD"
+		if len(args) > 0 {
+			ref = args[0]
+		}
+
+		entry, err := internal.ResolveRef(ref)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		output, err := outputFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		limit, _ := cmd.Flags().GetUint16("limit")
+		revisions, err := internal.ListRevisions(entry.ID, int(limit))
+		if err != nil {
+			return fmt.Errorf("failed to read entry history: %w", err)
+		}
+
+		if output != outputText {
+			return printJSON(output, internal.EntryHistory{Entry: entry, Revisions: revisions})
+		}
+
+		fmt.Println(entry.Detail())
+
+		if len(revisions) > 0 {
+			fmt.Println()
+			fmt.Println("history:")
+			for _, rev := range revisions {
+				fmt.Printf("  %s\n", rev.String())
+			}
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(showCmd)
+
+	showCmd.Flags().Uint16P("limit", "l", 20, "number of revisions of the entry history to show")
+	addJSONFlags(showCmd)
+}
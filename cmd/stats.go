@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"moodgit/internal"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "summarise your mood patterns",
+	Long: `summarise your mood patterns over a period of time.
+
+stats shows:
+- how many entries you logged and on how many days
+- mean, median and standard deviation of the intensity
+- how often each mood occurred and its mean intensity
+- your most used tags
+- the longest and the current logging streak
+- the hours of the day you log most often
+
+the period is the current week, month or year, everything, or a custom
+range given with --since and --until. all other log filters apply too.
+weeks start on monday unless MOODGIT_WEEK_START names another day.
+
+examples:
+  moodgit stats                          # everything
+  moodgit stats -p week                  # this week
+  moodgit stats -p month --tag work      # this month, work entries only
+  moodgit stats --since 2026-01-01 --until 2026-03-31
+  moodgit stats -p year --json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := internal.InitDB(); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		output, err := outputFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		filter, err := filterFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		period, _ := cmd.Flags().GetString("period")
+		if period != "all" && (cmd.Flags().Changed("since") || cmd.Flags().Changed("until")) {
+			return fmt.Errorf("--period can't be combined with --since or --until")
+		}
+
+		start, err := periodStart(period, internal.Now())
+		if err != nil {
+			return err
+		}
+		if !start.IsZero() {
+			filter.Since = start
+		}
+
+		stats, err := internal.GetStats(filter)
+		if err != nil {
+			return fmt.Errorf("failed to compute stats: %w", err)
+		}
+
+		if output != outputText {
+			return printJSON(output, stats)
+		}
+
+		fmt.Println(stats.String())
+		return nil
+	},
+}
+
+// periodStart returns where the current week, month or year began.
+func periodStart(period string, now time.Time) (time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch period {
+	case "all":
+		return time.Time{}, nil
+	case "week":
+		weekStart, err := internal.WeekStart()
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid MOODGIT_WEEK_START: %w", err)
+		}
+		return internal.StartOfWeek(today, weekStart), nil
+	case "month":
+		return today.AddDate(0, 0, 1-today.Day()), nil
+	case "year":
+		return today.AddDate(0, 0, 1-today.YearDay()), nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid --period %q, expected week, month, year or all", period)
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+
+	statsCmd.Flags().StringP("period", "p", "all", "summarise the current week, month, year or all entries")
+	addFilterFlags(statsCmd)
+	addJSONFlags(statsCmd)
+}
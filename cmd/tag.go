@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"fmt"
+	"moodgit/internal"
+
+	"github.com/spf13/cobra"
+)
+
+var tagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "manage the tags of your entries",
+	Long: `list, rename, merge and remove tags across all entries.
+
+renaming, merging and removing a tag changes every entry that carries
+it. each of those entries keeps its previous state in the reflog, so
+the change can be undone entry by entry with moodgit restore.`,
+}
+
+var tagListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "list all tags with their number of entries",
+	Long: `list all tags in use, the most used first.
+
+examples:
+  moodgit tag list
+  moodgit tag list --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := internal.InitDB(); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		output, err := outputFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		counts, err := internal.TagCounts()
+		if err != nil {
+			return fmt.Errorf("failed to list tags: %w", err)
+		}
+
+		if output != outputText {
+			return printJSONList(output, counts)
+		}
+
+		if len(counts) == 0 {
+			fmt.Println("no tags yet")
+			return nil
+		}
+
+		for _, tc := range counts {
+			fmt.Printf("%6d  %s\n", tc.Count, tc.Tag)
+		}
+
+		return nil
+	},
+}
+
+var tagRenameCmd = &cobra.Command{
+	Use:     "rename <old> <new>",
+	Aliases: []string{"mv"},
+	Short:   "rename a tag on every entry",
+	Long: `rename a tag on every entry carrying it. if the new name is already
+a tag, use moodgit tag merge instead.
+
+examples:
+  moodgit tag rename wrok work`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeTagArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := internal.InitDB(); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		n, err := internal.RenameTag(args[0], args[1])
+		if err != nil {
+			return fmt.Errorf("failed to rename tag: %w", err)
+		}
+
+		fmt.Printf("renamed %s to %s on %d entries\n", args[0], args[1], n)
+		return nil
+	},
+}
+
+var tagMergeCmd = &cobra.Command{
+	Use:   "merge <tag>... --into <tag>",
+	Short: "merge several tags into one",
+	Long: `replace each of the given tags by the --into tag on every entry. the
+target tag may be new or one of the merged tags.
+
+examples:
+  moodgit tag merge job office --into work
+  moodgit tag merge gym running --into exercise`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeTagArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := internal.InitDB(); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		into, _ := cmd.Flags().GetString("into")
+		if into == "" {
+			return fmt.Errorf("--into is required")
+		}
+
+		n, err := internal.MergeTags(args, into)
+		if err != nil {
+			return fmt.Errorf("failed to merge tags: %w", err)
+		}
+
+		fmt.Printf("merged %d tags into %s on %d entries\n", len(args), into, n)
+		return nil
+	},
+}
+
+var tagRmCmd = &cobra.Command{
+	Use:     "rm <tag>",
+	Aliases: []string{"remove", "delete"},
+	Short:   "remove a tag from every entry",
+	Long: `remove a tag from every entry carrying it. the entries themselves
+are kept. you are asked for confirmation unless --yes is given.
+
+examples:
+  moodgit tag rm temp
+  moodgit tag rm temp -y`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeTagArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := internal.InitDB(); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		yes, _ := cmd.Flags().GetBool("yes")
+		if !yes {
+			ok, err := confirm(fmt.Sprintf("remove tag %s from all entries?", args[0]))
+			if err != nil {
+				return err
+			}
+			if !ok {
+				fmt.Println("aborted")
+				return nil
+			}
+		}
+
+		n, err := internal.RemoveTag(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to remove tag: %w", err)
+		}
+
+		fmt.Printf("removed %s from %d entries\n", args[0], n)
+		return nil
+	},
+}
+
+// completeTagArgs completes existing tags as positional arguments, except for
+// the new name of tag rename.
+func completeTagArgs(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if cmd.Name() != "merge" && len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeTags(cmd, args, toComplete)
+}
+
+func init() {
+	rootCmd.AddCommand(tagCmd)
+	tagCmd.AddCommand(tagListCmd, tagRenameCmd, tagMergeCmd, tagRmCmd)
+
+	addJSONFlags(tagListCmd)
+
+	tagMergeCmd.Flags().String("into", "", "the tag to merge into")
+	tagMergeCmd.RegisterFlagCompletionFunc("into", completeTags)
+
+	tagRmCmd.Flags().BoolP("yes", "y", false, "remove without asking for confirmation")
+}
@@ -0,0 +1,314 @@
+package internal
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// adapters read the csv exports of other mood apps
+const (
+	AdapterDaylio  = "daylio"
+	AdapterGeneric = "generic"
+)
+
+var Adapters = []string{AdapterDaylio, AdapterGeneric}
+
+// the intensity of a mapped label that doesn't set one
+const defaultMappedIntensity = 5
+
+// MappedMood is what a foreign mood label turns into.
+type MappedMood struct {
+	Mood      Mood  `json:"mood"`
+	Intensity *int8 `json:"intensity,omitempty"`
+}
+
+// MoodMapping maps the lowercased mood labels of another app, or the steps
+// of its scale ("1" to "5"), to moods and intensities.
+type MoodMapping map[string]MappedMood
+
+// the default labels of daylio and a plain 1 to 5 scale, 1 being the worst
+var (
+	daylioMapping = MoodMapping{
+		"rad":   mapped(MoodExcited, 9),
+		"good":  mapped(MoodHappy, 7),
+		"meh":   mapped(MoodNeutral, 5),
+		"bad":   mapped(MoodSad, 6),
+		"awful": mapped(MoodSad, 9),
+	}
+
+	scaleMapping = MoodMapping{
+		"1": mapped(MoodSad, 9),
+		"2": mapped(MoodSad, 6),
+		"3": mapped(MoodNeutral, 5),
+		"4": mapped(MoodHappy, 7),
+		"5": mapped(MoodExcited, 9),
+	}
+)
+
+func mapped(mood Mood, intensity int8) MappedMood {
+	return MappedMood{Mood: mood, Intensity: &intensity}
+}
+
+// DefaultMoodMapping returns the mapping the adapters start from, daylio's
+// labels and a 1 to 5 scale. a mapping file only has to list what differs.
+func DefaultMoodMapping() MoodMapping {
+	mapping := MoodMapping{}
+	maps.Copy(mapping, scaleMapping)
+	maps.Copy(mapping, daylioMapping)
+	return mapping
+}
+
+// LoadMoodMapping reads a mapping file like
+//
+//	{"moods": {"rad": {"mood": "excited", "intensity": 9}, "meh": {"mood": "calm"}}}
+//
+// and adds it to base. the moods have to exist, intensities default to 5.
+func LoadMoodMapping(path string, base MoodMapping) (MoodMapping, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file struct {
+		Moods map[string]MappedMood `json:"moods"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid mapping file: %w", err)
+	}
+
+	mapping := maps.Clone(base)
+	for label, target := range file.Moods {
+		mood, err := ResolveMood(target.Mood)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mapping for %q: %w", label, err)
+		}
+		target.Mood = mood
+
+		if target.Intensity != nil {
+			if err := ValidateIntensity(*target.Intensity); err != nil {
+				return nil, fmt.Errorf("invalid mapping for %q: %w", label, err)
+			}
+		}
+
+		mapping[normalizeLabel(label)] = target
+	}
+
+	return mapping, nil
+}
+
+func normalizeLabel(label string) string {
+	return strings.ToLower(strings.TrimSpace(label))
+}
+
+// lookup maps a label, falling back to a moodgit mood of the same name.
+func (m MoodMapping) lookup(label string) (Mood, int8, bool) {
+	target, ok := m[normalizeLabel(label)]
+	if !ok {
+		if mood := normalizeLabel(label); slices.Contains(MoodNames(), mood) {
+			return mood, defaultMappedIntensity, true
+		}
+		return "", 0, false
+	}
+
+	if target.Intensity == nil {
+		return target.Mood, defaultMappedIntensity, true
+	}
+	return target.Mood, *target.Intensity, true
+}
+
+// AdapterImport is what an adapter read. Unmapped counts the entries of
+// every label the mapping doesn't know, those entries aren't in Records.
+type AdapterImport struct {
+	Records  []ImportRecord
+	Unmapped map[string]int
+}
+
+// UnmappedLabels returns the unmapped labels, the most used first.
+func (a AdapterImport) UnmappedLabels() []string {
+	labels := slices.Collect(maps.Keys(a.Unmapped))
+	slices.SortFunc(labels, func(x, y string) int {
+		if a.Unmapped[x] != a.Unmapped[y] {
+			return a.Unmapped[y] - a.Unmapped[x]
+		}
+		return strings.Compare(x, y)
+	})
+	return labels
+}
+
+// ReadAdapterImport reads the csv export of another app with the adapter.
+func ReadAdapterImport(r io.Reader, adapter string, mapping MoodMapping) (AdapterImport, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	switch adapter {
+	case AdapterDaylio:
+		return readDaylio(reader, mapping)
+	case AdapterGeneric:
+		return readGeneric(reader, mapping)
+	}
+	return AdapterImport{}, fmt.Errorf("unknown app %q, expected one of %s", adapter, strings.Join(Adapters, ", "))
+}
+
+// readDaylio reads daylio's "export entries (csv)": full_date (or year and
+// date in older versions), time, mood, activities separated by " | ",
+// note_title and note.
+func readDaylio(reader *csv.Reader, mapping MoodMapping) (AdapterImport, error) {
+	result := AdapterImport{Unmapped: map[string]int{}}
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return result, nil
+		}
+		return result, fmt.Errorf("invalid csv: %w", err)
+	}
+
+	column := map[string]int{}
+	for i, name := range header {
+		column[normalizeLabel(strings.TrimPrefix(name, "\ufeff"))] = i
+	}
+
+	for _, required := range []string{"time", "mood"} {
+		if _, ok := column[required]; !ok {
+			return result, fmt.Errorf("no %q column, is this a daylio export?", required)
+		}
+	}
+
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return result, fmt.Errorf("invalid csv: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		source := fmt.Sprintf("line %d", line)
+
+		field := func(name string) string {
+			if i, ok := column[name]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+
+		label := field("mood")
+		mood, intensity, ok := mapping.lookup(label)
+		if !ok {
+			result.Unmapped[normalizeLabel(label)]++
+			continue
+		}
+
+		createdAt, err := parseDaylioTime(field("full_date"), field("year"), field("date"), field("time"))
+		if err != nil {
+			return result, fmt.Errorf("%s: %w", source, err)
+		}
+
+		var tags []string
+		if activities := field("activities"); activities != "" {
+			tags = strings.Split(activities, "|")
+		}
+
+		message := strings.TrimSpace(field("note_title") + "\n\n" + field("note"))
+		message = strings.NewReplacer("<br>", "\n", "<br/>", "\n", "<br />", "\n").Replace(message)
+
+		result.Records = append(result.Records, ImportRecord{Source: source, Entry: Entry{
+			CreatedAt: createdAt,
+			UpdatedAt: createdAt,
+			Mood:      mood,
+			Intensity: intensity,
+			Message:   message,
+			Tags:      tags,
+		}})
+	}
+
+	return result, nil
+}
+
+var (
+	daylioDateLayouts = []string{"January 2 2006", "2 January 2006", "Jan 2 2006", "2 Jan 2006"}
+	daylioTimeLayouts = []string{"3:04 PM", "3:04PM", "15:04"}
+)
+
+// parseDaylioTime reads the date and time columns of daylio in the display
+// location.
+func parseDaylioTime(fullDate, year, date, clock string) (time.Time, error) {
+	var day time.Time
+	var err error
+
+	if fullDate != "" {
+		day, err = time.ParseInLocation(time.DateOnly, fullDate, displayLocation)
+	} else {
+		for _, layout := range daylioDateLayouts {
+			if day, err = time.ParseInLocation(layout, date+" "+year, displayLocation); err == nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unrecognized date %q", strings.TrimSpace(fullDate+" "+date+" "+year))
+	}
+
+	for _, layout := range daylioTimeLayouts {
+		if t, err := time.Parse(layout, strings.ToUpper(clock)); err == nil {
+			return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, displayLocation), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognized time %q", clock)
+}
+
+// readGeneric reads rows of date, mood label and an optional note. a first
+// row whose date can't be read is taken as the header.
+func readGeneric(reader *csv.Reader, mapping MoodMapping) (AdapterImport, error) {
+	result := AdapterImport{Unmapped: map[string]int{}}
+
+	for first := true; ; first = false {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return result, fmt.Errorf("invalid csv: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		source := fmt.Sprintf("line %d", line)
+
+		if len(row) < 2 {
+			return result, fmt.Errorf("%s: expected date, mood and an optional note", source)
+		}
+
+		createdAt, err := parseImportTime(strings.TrimPrefix(row[0], "\ufeff"))
+		if err != nil {
+			if first {
+				continue
+			}
+			return result, fmt.Errorf("%s: %w", source, err)
+		}
+
+		mood, intensity, ok := mapping.lookup(row[1])
+		if !ok {
+			result.Unmapped[normalizeLabel(row[1])]++
+			continue
+		}
+
+		entry := Entry{CreatedAt: createdAt, UpdatedAt: createdAt, Mood: mood, Intensity: intensity}
+		if len(row) > 2 {
+			entry.Message = strings.TrimSpace(row[2])
+		}
+
+		result.Records = append(result.Records, ImportRecord{Source: source, Entry: entry})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,235 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDaylioTime(t *testing.T) {
+	inUTC(t)
+
+	tests := []struct {
+		fullDate, year, date, clock string
+		want                        time.Time
+	}{
+		{"2026-10-16", "", "", "9:30 PM", time.Date(2026, 10, 16, 21, 30, 0, 0, time.UTC)},
+		{"2026-10-16", "2025", "March 1", "9:30 am", time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)},
+		{"2026-10-16", "", "", "12:05AM", time.Date(2026, 10, 16, 0, 5, 0, 0, time.UTC)},
+		{"2026-10-16", "", "", "21:30", time.Date(2026, 10, 16, 21, 30, 0, 0, time.UTC)},
+		{"", "2026", "October 16", "8:00 AM", time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)},
+		{"", "2026", "16 October", "8:00 AM", time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)},
+		{"", "2026", "Oct 6", "20:15", time.Date(2026, 10, 6, 20, 15, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		got, err := parseDaylioTime(tt.fullDate, tt.year, tt.date, tt.clock)
+		if err != nil {
+			t.Errorf("parseDaylioTime(%q, %q, %q, %q): %v", tt.fullDate, tt.year, tt.date, tt.clock, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseDaylioTime(%q, %q, %q, %q) = %v, want %v", tt.fullDate, tt.year, tt.date, tt.clock, got, tt.want)
+		}
+	}
+
+	invalid := []struct {
+		fullDate, year, date, clock string
+		want                        string
+	}{
+		{"16/10/2026", "", "", "9:30 PM", "unrecognized date"},
+		{"", "", "October 16", "9:30 PM", "unrecognized date"},
+		{"", "", "", "9:30 PM", "unrecognized date"},
+		{"2026-10-16", "", "", "25:00", "unrecognized time"},
+		{"2026-10-16", "", "", "", "unrecognized time"},
+	}
+
+	for _, tt := range invalid {
+		_, err := parseDaylioTime(tt.fullDate, tt.year, tt.date, tt.clock)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("parseDaylioTime(%q, %q, %q, %q) = %v, want %s", tt.fullDate, tt.year, tt.date, tt.clock, err, tt.want)
+		}
+	}
+}
+
+func TestMoodMappingLookup(t *testing.T) {
+	openTestDB(t)
+
+	mapping := DefaultMoodMapping()
+	mapping["fine"] = MappedMood{Mood: MoodCalm}
+
+	tests := []struct {
+		label     string
+		mood      Mood
+		intensity int8
+		ok        bool
+	}{
+		{"rad", MoodExcited, 9, true},
+		{" Awful ", MoodSad, 9, true},
+		{"MEH", MoodNeutral, 5, true},
+		{"3", MoodNeutral, 5, true},
+		{"1", MoodSad, 9, true},
+		// mapped without an intensity
+		{"fine", MoodCalm, defaultMappedIntensity, true},
+		// moodgit's own moods need no mapping
+		{"Anxious", MoodAnxious, defaultMappedIntensity, true},
+		{"6", "", 0, false},
+		{"anx", "", 0, false},
+		{"", "", 0, false},
+	}
+
+	for _, tt := range tests {
+		mood, intensity, ok := mapping.lookup(tt.label)
+		if mood != tt.mood || intensity != tt.intensity || ok != tt.ok {
+			t.Errorf("lookup(%q) = %q, %d, %v, want %q, %d, %v", tt.label, mood, intensity, ok, tt.mood, tt.intensity, tt.ok)
+		}
+	}
+}
+
+func TestLoadMoodMapping(t *testing.T) {
+	openTestDB(t)
+
+	tests := []struct {
+		name    string
+		file    string
+		label   string
+		mood    Mood
+		invalid bool
+	}{
+		{name: "override", file: `{"moods": {"Meh": {"mood": "calm"}}}`, label: "meh", mood: MoodCalm},
+		{name: "new label", file: `{"moods": {"amazing": {"mood": "exc", "intensity": 10}}}`, label: "amazing", mood: MoodExcited},
+		{name: "defaults kept", file: `{"moods": {}}`, label: "rad", mood: MoodExcited},
+		{name: "unknown mood", file: `{"moods": {"meh": {"mood": "bored"}}}`, invalid: true},
+		{name: "bad intensity", file: `{"moods": {"meh": {"mood": "calm", "intensity": 11}}}`, invalid: true},
+		{name: "not json", file: `moods: {}`, invalid: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "mapping.json")
+			if err := os.WriteFile(path, []byte(tt.file), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			mapping, err := LoadMoodMapping(path, DefaultMoodMapping())
+			if tt.invalid {
+				if err == nil {
+					t.Errorf("LoadMoodMapping succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if mood, _, _ := mapping.lookup(tt.label); mood != tt.mood {
+				t.Errorf("%q maps to %q, want %q", tt.label, mood, tt.mood)
+			}
+		})
+	}
+}
+
+func TestReadAdapterImport(t *testing.T) {
+	openTestDB(t)
+	inUTC(t)
+
+	tests := []struct {
+		name     string
+		adapter  string
+		input    string
+		messages []string
+		tags     [][]string
+		unmapped []string
+	}{
+		{
+			name:    "daylio",
+			adapter: AdapterDaylio,
+			input: "\ufefffull_date,date,weekday,time,mood,activities,note_title,note\n" +
+				"2026-10-16,October 16,Friday,9:30 PM,rad,friends | dinner,Great day,lots of<br>laughs\n" +
+				"2026-10-15,October 15,Thursday,8:00 AM,sleepy,,,\n" +
+				"2026-10-14,October 14,Wednesday,8:00 AM,sleepy,,,\n" +
+				"2026-10-13,October 13,Tuesday,8:00 AM,bored,,,\n" +
+				"2026-10-12,October 12,Monday,7:45 AM,Meh,,,\n",
+			messages: []string{"Great day\n\nlots of\nlaughs", ""},
+			tags:     [][]string{{"friends ", " dinner"}, nil},
+			unmapped: []string{"sleepy", "bored"},
+		},
+		{
+			name:    "old daylio",
+			adapter: AdapterDaylio,
+			input: "year,date,weekday,time,mood,activities,note\n" +
+				"2019,Oct 16,Wednesday,21:30,good,,\"a, b\"\n",
+			messages: []string{"a, b"},
+			tags:     [][]string{nil},
+		},
+		{
+			name:     "generic with a header",
+			adapter:  AdapterGeneric,
+			input:    "date,mood,note\n2026-10-16 09:30,4, a walk \n2026-10-17,7\n2026-10-18,calm\n",
+			messages: []string{"a walk", ""},
+			tags:     [][]string{nil, nil},
+			unmapped: []string{"7"},
+		},
+		{
+			name:     "generic without a header",
+			adapter:  AdapterGeneric,
+			input:    "2026-10-16,bad\n",
+			messages: []string{""},
+			tags:     [][]string{nil},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ReadAdapterImport(strings.NewReader(tt.input), tt.adapter, DefaultMoodMapping())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var messages []string
+			var tags [][]string
+			for _, r := range result.Records {
+				messages = append(messages, r.Entry.Message)
+				tags = append(tags, r.Entry.Tags)
+			}
+			if !slices.Equal(messages, tt.messages) {
+				t.Errorf("read messages %q, want %q", messages, tt.messages)
+			}
+			if !slices.EqualFunc(tags, tt.tags, slices.Equal) {
+				t.Errorf("read tags %q, want %q", tags, tt.tags)
+			}
+			if labels := result.UnmappedLabels(); !slices.Equal(labels, tt.unmapped) {
+				t.Errorf("unmapped labels are %q, want %q", labels, tt.unmapped)
+			}
+		})
+	}
+}
+
+func TestReadAdapterImportErrors(t *testing.T) {
+	inUTC(t)
+
+	tests := []struct {
+		name    string
+		adapter string
+		input   string
+		want    string
+	}{
+		{"unknown app", "moodpanda", "", `unknown app "moodpanda"`},
+		{"not a daylio export", AdapterDaylio, "date,feeling\n", `no "time" column`},
+		{"bad daylio date", AdapterDaylio, "full_date,time,mood\n2026-10-16,9:30 PM,rad\nyesterday,9:30 PM,rad\n", "line 3: unrecognized date"},
+		{"short generic row", AdapterGeneric, "2026-10-16\n", "line 1: expected date, mood"},
+		{"bad generic date", AdapterGeneric, "2026-10-16,rad\nlast week,rad\n", "line 2: unrecognized timestamp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadAdapterImport(strings.NewReader(tt.input), tt.adapter, DefaultMoodMapping())
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ReadAdapterImport = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package internal
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrEmptyMessage is returned when the message written in the editor is
+	// empty.
+	ErrEmptyMessage = errors.New("aborting due to empty message")
+	// ErrEmptyFile is returned when everything in the editor was deleted.
+	ErrEmptyFile = errors.New("aborting due to empty file")
+)
+
+const composeHelp = `# write the message of your entry above. lines starting with '#' are
+# ignored and %s aborts.
+#
+# mood, intensity (%d-%d) and tags (comma separated) can be changed in the
+# header. moods: %s
+`
+
+// ComposeEntry lets the user write an entry in their editor, like git commit
+// without -m. the header of the template is filled in from the patch and the
+// returned patch holds the header as edited, with nil for fields left empty,
+// and the message.
+func ComposeEntry(patch EntryPatch) (EntryPatch, error) {
+	composed, err := compose(patch, "an empty message")
+	if errors.Is(err, ErrEmptyFile) {
+		return EntryPatch{}, ErrEmptyMessage
+	}
+	if err != nil {
+		return EntryPatch{}, err
+	}
+
+	if *composed.Message == "" {
+		return EntryPatch{}, ErrEmptyMessage
+	}
+	return composed, nil
+}
+
+// ComposeEdit is ComposeEntry for an existing entry, its message may be
+// emptied and only an empty file aborts.
+func ComposeEdit(patch EntryPatch) (EntryPatch, error) {
+	return compose(patch, "an empty file")
+}
+
+func compose(patch EntryPatch, aborts string) (EntryPatch, error) {
+	file, err := os.CreateTemp("", "moodgit-entry-*.md")
+	if err != nil {
+		return EntryPatch{}, err
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString(composeTemplate(patch, aborts))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return EntryPatch{}, err
+	}
+
+	if err := runEditor(file.Name()); err != nil {
+		return EntryPatch{}, err
+	}
+
+	text, err := os.ReadFile(file.Name())
+	if err != nil {
+		return EntryPatch{}, err
+	}
+
+	return parseComposed(string(text))
+}
+
+func composeTemplate(patch EntryPatch, aborts string) string {
+	var mood, intensity, tags, message string
+	if patch.Mood != nil {
+		mood = *patch.Mood
+	}
+	if patch.Intensity != nil {
+		intensity = strconv.Itoa(int(*patch.Intensity))
+	}
+	if patch.Tags != nil {
+		tags = strings.Join(*patch.Tags, ", ")
+	}
+	if patch.Message != nil {
+		message = *patch.Message
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "---\nmood: %s\nintensity: %s\ntags: %s\n---\n", mood, intensity, tags)
+	fmt.Fprintf(&b, "%s\n\n", message)
+	fmt.Fprintf(&b, composeHelp, aborts, MinIntensity, MaxIntensity, strings.Join(MoodNames(), ", "))
+
+	return b.String()
+}
+
+// parseComposed reads the front matter header and the message of a composed
+// entry, the message may be empty. comment lines are dropped everywhere.
+func parseComposed(text string) (EntryPatch, error) {
+	var patch EntryPatch
+	var lines []string
+	empty := true
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+			empty = empty && line == ""
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return EntryPatch{}, err
+	}
+
+	if empty {
+		return EntryPatch{}, ErrEmptyFile
+	}
+
+	if len(lines) > 0 && lines[0] == "---" {
+		end := -1
+		for i := 1; i < len(lines); i++ {
+			if lines[i] == "---" {
+				end = i
+				break
+			}
+		}
+		if end < 0 {
+			return EntryPatch{}, fmt.Errorf("the header isn't closed with a --- line")
+		}
+
+		for _, line := range lines[1:end] {
+			if err := patch.setHeader(line); err != nil {
+				return EntryPatch{}, err
+			}
+		}
+		lines = lines[end+1:]
+	}
+
+	message := strings.TrimSpace(strings.Join(lines, "\n"))
+	patch.Message = &message
+
+	return patch, nil
+}
+
+func (p *EntryPatch) setHeader(line string) error {
+	if strings.TrimSpace(line) == "" {
+		return nil
+	}
+
+	key, value, ok := strings.Cut(line, ":")
+	if !ok {
+		return fmt.Errorf("invalid header line %q, expected key: value", line)
+	}
+	key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+
+	switch key {
+	case "mood":
+		if value != "" {
+			p.Mood = &value
+		}
+	case "intensity":
+		if value != "" {
+			n, err := strconv.ParseInt(value, 10, 8)
+			if err != nil {
+				return fmt.Errorf("invalid intensity %q in the header", value)
+			}
+			intensity := int8(n)
+			p.Intensity = &intensity
+		}
+	case "tags":
+		tags := strings.Split(value, ",")
+		p.Tags = &tags
+	default:
+		return fmt.Errorf("unknown header %q, expected mood, intensity or tags", key)
+	}
+
+	return nil
+}
+
+// runEditor opens the file in $VISUAL or $EDITOR, falling back to vi. the
+// command goes through the shell so it may carry arguments, e.g. "code -w".
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	cmd := exec.Command("sh", "-c", editor+` "$@"`, editor, path)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor %q failed: %w", editor, err)
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package internal
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// describePatch prints the fields set in a patch, for comparing patches.
+func describePatch(p EntryPatch) []any {
+	var fields []any
+	if p.Mood != nil {
+		fields = append(fields, "mood", *p.Mood)
+	}
+	if p.Intensity != nil {
+		fields = append(fields, "intensity", *p.Intensity)
+	}
+	if p.Tags != nil {
+		fields = append(fields, "tags", *p.Tags)
+	}
+	if p.Message != nil {
+		fields = append(fields, "message", *p.Message)
+	}
+	return fields
+}
+
+func equalPatch(a, b EntryPatch) bool {
+	return slices.EqualFunc(describePatch(a), describePatch(b), func(x, y any) bool {
+		if xs, ok := x.([]string); ok {
+			ys, ok := y.([]string)
+			return ok && slices.Equal(xs, ys)
+		}
+		return x == y
+	})
+}
+
+func TestParseComposed(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want EntryPatch
+	}{
+		{
+			name: "message only",
+			text: "a quiet evening\n",
+			want: EntryPatch{Message: ptr("a quiet evening")},
+		},
+		{
+			name: "header",
+			text: "---\nmood: calm\nintensity: 4\ntags: home, family\n---\na quiet evening\n\nread a book\n",
+			want: EntryPatch{
+				Mood:      ptr("calm"),
+				Intensity: ptr(int8(4)),
+				Tags:      ptr([]string{"home", " family"}),
+				Message:   ptr("a quiet evening\n\nread a book"),
+			},
+		},
+		{
+			name: "empty header values",
+			text: "---\nmood:\nintensity: \ntags:\n---\nhello\n",
+			want: EntryPatch{Tags: ptr([]string{""}), Message: ptr("hello")},
+		},
+		{
+			name: "header keys ignore case and blank lines",
+			text: "---\n  Mood : sad\n\nINTENSITY:2\n---\nhello",
+			want: EntryPatch{Mood: ptr("sad"), Intensity: ptr(int8(2)), Message: ptr("hello")},
+		},
+		{
+			name: "comments dropped",
+			text: "---\n# the header\nmood: sad\n---\n# above\nhello\n# between\nworld\r\n# mood: happy\n",
+			want: EntryPatch{Mood: ptr("sad"), Message: ptr("hello\nworld")},
+		},
+		{
+			name: "empty message",
+			text: "---\nmood: sad\nintensity: 2\ntags:\n---\n\n# write the message above\n",
+			want: EntryPatch{Mood: ptr("sad"), Intensity: ptr(int8(2)), Tags: ptr([]string{""}), Message: ptr("")},
+		},
+		{
+			name: "dashes later in the message",
+			text: "hello\n---\nmood: sad\n---\n",
+			want: EntryPatch{Message: ptr("hello\n---\nmood: sad\n---")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseComposed(tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !equalPatch(got, tt.want) {
+				t.Errorf("parseComposed = %v, want %v", describePatch(got), describePatch(tt.want))
+			}
+		})
+	}
+}
+
+func TestParseComposedErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		empty bool // the error is ErrEmptyFile
+	}{
+		{name: "empty", text: "", empty: true},
+		{name: "blank lines", text: "\n  \n\t\n", empty: true},
+		{name: "only comments", text: "# nothing\n\n# here\n", empty: true},
+		{name: "unclosed header", text: "---\nmood: sad\nhello\n"},
+		{name: "bad intensity", text: "---\nintensity: high\n---\nhello\n"},
+		{name: "intensity out of range", text: "---\nintensity: 300\n---\nhello\n"},
+		{name: "unknown header", text: "---\nweather: sunny\n---\nhello\n"},
+		{name: "header without a colon", text: "---\nmood sad\n---\nhello\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseComposed(tt.text)
+			if err == nil {
+				t.Fatalf("parseComposed = %v, want an error", describePatch(got))
+			}
+			if errors.Is(err, ErrEmptyFile) != tt.empty {
+				t.Errorf("parseComposed = %v, want ErrEmptyFile %v", err, tt.empty)
+			}
+		})
+	}
+}
+
+func TestComposeTemplate(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch EntryPatch
+		want  EntryPatch
+	}{
+		{
+			name:  "new entry",
+			patch: EntryPatch{},
+			want:  EntryPatch{Tags: ptr([]string{""}), Message: ptr("")},
+		},
+		{
+			name: "existing entry",
+			patch: EntryPatch{
+				Mood:      ptr(MoodHappy),
+				Intensity: ptr(int8(8)),
+				Tags:      ptr([]string{"work", "achievement"}),
+				Message:   ptr("got a promotion\n\nthe team celebrated"),
+			},
+			want: EntryPatch{
+				Mood:      ptr(MoodHappy),
+				Intensity: ptr(int8(8)),
+				Tags:      ptr([]string{"work", " achievement"}),
+				Message:   ptr("got a promotion\n\nthe team celebrated"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseComposed(composeTemplate(tt.patch, "an empty file"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !equalPatch(got, tt.want) {
+				t.Errorf("the template read back as %v, want %v", describePatch(got), describePatch(tt.want))
+			}
+		})
+	}
+}
+
+func TestEntryPatchChanges(t *testing.T) {
+	openTestDB(t)
+
+	entry := Entry{
+		Mood:      MoodHappy,
+		Intensity: 8,
+		Message:   "got a promotion",
+		Tags:      []string{"work", "achievement"},
+	}
+
+	tests := []struct {
+		name  string
+		patch EntryPatch
+		want  EntryPatch
+	}{
+		{
+			name: "unchanged",
+			patch: EntryPatch{
+				Mood:      ptr("Hap"),
+				Intensity: ptr(int8(8)),
+				Tags:      ptr([]string{"work", " achievement", ""}),
+				Message:   ptr("got a promotion"),
+			},
+			want: EntryPatch{},
+		},
+		{
+			name:  "mood changed",
+			patch: EntryPatch{Mood: ptr("calm"), Intensity: ptr(int8(8))},
+			want:  EntryPatch{Mood: ptr("calm")},
+		},
+		{
+			name:  "tags reordered",
+			patch: EntryPatch{Tags: ptr([]string{"achievement", "work"})},
+			want:  EntryPatch{Tags: ptr([]string{"achievement", "work"})},
+		},
+		{
+			name:  "message emptied",
+			patch: EntryPatch{Message: ptr("")},
+			want:  EntryPatch{Message: ptr("")},
+		},
+		{
+			// left for the update to report
+			name:  "invalid mood",
+			patch: EntryPatch{Mood: ptr("furious")},
+			want:  EntryPatch{Mood: ptr("furious")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.patch.Changes(entry); !equalPatch(got, tt.want) {
+				t.Errorf("changes = %v, want %v", describePatch(got), describePatch(tt.want))
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrFutureTimestamp = errors.New("timestamp is in the future")
+
+var absoluteLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006/01/02 15:04:05",
+	"2006/01/02 15:04",
+}
+
+var dateLayouts = []string{
+	"2006-01-02",
+	"2006/01/02",
+}
+
+var (
+	relativeRe  = regexp.MustCompile(`^-\s*(\d+)\s*([a-z]+)$|^(\d+)\s*([a-z]+)\s+ago$`)
+	clockTimeRe = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?\s*(am|pm)?$`)
+)
+
+var relativeUnits = map[string]time.Duration{
+	"s": time.Second, "sec": time.Second, "secs": time.Second, "second": time.Second, "seconds": time.Second,
+	"m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"h": time.Hour, "hr": time.Hour, "hrs": time.Hour, "hour": time.Hour, "hours": time.Hour,
+	"d": 24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour, "week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour,
+}
+
+// months differ in length, they go back by calendar months. "m" is taken by
+// minutes.
+var relativeMonthUnits = []string{"mo", "mos", "month", "months"}
+
+// ParseTime understands absolute timestamps (RFC3339, "2026-10-16 09:30",
+// "2026-10-16"), relative ones ("-3h", "2 days ago") and day words with an
+// optional time of day ("yesterday 9am", "monday 14:30", "noon").
+// inputs without a zone are read in the location of now.
+func ParseTime(input string, now time.Time) (time.Time, error) {
+	t, _, err := parseTime(input, now)
+	return t, err
+}
+
+// ParseUntil parses the upper bound of a range. inputs naming a whole day
+// ("2026-10-16", "yesterday") include that day, so the returned exclusive
+// bound is the start of the following day.
+func ParseUntil(input string, now time.Time) (time.Time, error) {
+	t, wholeDay, err := parseTime(input, now)
+	if err != nil || !wholeDay {
+		return t, err
+	}
+	return t.AddDate(0, 0, 1), nil
+}
+
+func parseTime(input string, now time.Time) (time.Time, bool, error) {
+	s := strings.ToLower(strings.TrimSpace(input))
+	if s == "" {
+		return time.Time{}, false, fmt.Errorf("empty date")
+	}
+
+	if s == "now" {
+		return now, false, nil
+	}
+
+	for _, layout := range absoluteLayouts {
+		if t, err := time.ParseInLocation(layout, strings.ToUpper(s), now.Location()); err == nil {
+			return t, false, nil
+		}
+	}
+
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, true, nil
+		}
+	}
+
+	if m := relativeRe.FindStringSubmatch(s); m != nil {
+		amount, unit := m[1], m[2]
+		if amount == "" {
+			amount, unit = m[3], m[4]
+		}
+
+		n, _ := strconv.Atoi(amount)
+		if slices.Contains(relativeMonthUnits, unit) {
+			return now.AddDate(0, -n, 0), false, nil
+		}
+
+		d, ok := relativeUnits[unit]
+		if !ok {
+			return time.Time{}, false, fmt.Errorf("unknown time unit %q in %q", unit, input)
+		}
+		return now.Add(-time.Duration(n) * d), false, nil
+	}
+
+	day, clock, _ := strings.Cut(s, " ")
+	base, ok := parseDayWord(day, now)
+	if !ok {
+		// a bare time of day means today
+		base, clock = startOfDay(now), s
+	}
+
+	if clock == "" {
+		return base, true, nil
+	}
+
+	hour, minute, err := parseClock(strings.TrimSpace(clock))
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("unrecognised date %q", input)
+	}
+
+	return time.Date(base.Year(), base.Month(), base.Day(), hour, minute, 0, 0, now.Location()), false, nil
+}
+
+// ParsePastTime is ParseTime for moments that must already have happened.
+func ParsePastTime(input string, now time.Time) (time.Time, error) {
+	t, err := ParseTime(input, now)
+	if err != nil {
+		return t, err
+	}
+
+	if t.After(now) {
+		return t, fmt.Errorf("%w: %s", ErrFutureTimestamp, t.Format("2006/01/02 15:04"))
+	}
+
+	return t, nil
+}
+
+func parseDayWord(word string, now time.Time) (time.Time, bool) {
+	today := startOfDay(now)
+
+	switch word {
+	case "today":
+		return today, true
+	case "yesterday":
+		return today.AddDate(0, 0, -1), true
+	}
+
+	// weekday names refer to the most recent one, today included
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		name := strings.ToLower(wd.String())
+		if word == name || word == name[:3] {
+			back := (int(today.Weekday()) - int(wd) + 7) % 7
+			return today.AddDate(0, 0, -back), true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+func parseClock(s string) (int, int, error) {
+	switch s {
+	case "noon":
+		return 12, 0, nil
+	case "midnight":
+		return 0, 0, nil
+	}
+
+	m := clockTimeRe.FindStringSubmatch(s)
+	if m == nil {
+		return 0, 0, fmt.Errorf("invalid time of day %q", s)
+	}
+
+	hour, _ := strconv.Atoi(m[1])
+	minute := 0
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+
+	switch m[3] {
+	case "am":
+		if hour < 1 || hour > 12 {
+			return 0, 0, fmt.Errorf("invalid time of day %q", s)
+		}
+		if hour == 12 {
+			hour = 0
+		}
+	case "pm":
+		if hour < 1 || hour > 12 {
+			return 0, 0, fmt.Errorf("invalid time of day %q", s)
+		}
+		if hour != 12 {
+			hour += 12
+		}
+	}
+
+	if hour > 23 || minute > 59 {
+		return 0, 0, fmt.Errorf("invalid time of day %q", s)
+	}
+
+	return hour, minute, nil
+}
+
+// WeekStart is the first day of the week, set with $MOODGIT_WEEK_START
+// (default monday).
+func WeekStart() (time.Weekday, error) {
+	name := os.Getenv("MOODGIT_WEEK_START")
+	if name == "" {
+		return time.Monday, nil
+	}
+	return ParseWeekday(name)
+}
+
+func ParseWeekday(name string) (time.Weekday, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		full := strings.ToLower(wd.String())
+		if name == full || name == full[:3] {
+			return wd, nil
+		}
+	}
+	return time.Sunday, fmt.Errorf("unknown weekday %q", name)
+}
+
+// StartOfWeek returns midnight of the first day of the week containing t.
+func StartOfWeek(t time.Time, weekStart time.Weekday) time.Time {
+	day := startOfDay(t)
+	return day.AddDate(0, 0, -((int(day.Weekday())-int(weekStart))+7)%7)
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,160 @@
+package internal
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// a friday afternoon two hours east of utc
+var testZone = time.FixedZone("UTC+2", 2*60*60)
+var testNow = time.Date(2026, 10, 16, 15, 45, 30, 0, testZone)
+
+func at(year int, month time.Month, day, hour, minute int) time.Time {
+	return time.Date(year, month, day, hour, minute, 0, 0, testZone)
+}
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		input string
+		want  time.Time
+	}{
+		{"now", testNow},
+		{"2026-10-16T09:30:00Z", time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)},
+		{"2026-10-16t09:30:00z", time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)},
+		{"2026-10-16 09:30", at(2026, 10, 16, 9, 30)},
+		{"2026/10/01 21:15:00", time.Date(2026, 10, 1, 21, 15, 0, 0, testZone)},
+		{"2026-10-16", at(2026, 10, 16, 0, 0)},
+		{"-3h", testNow.Add(-3 * time.Hour)},
+		{"- 90 min", testNow.Add(-90 * time.Minute)},
+		{"2 days ago", testNow.AddDate(0, 0, -2)},
+		{"1 week ago", testNow.AddDate(0, 0, -7)},
+		{"-1mo", testNow.AddDate(0, -1, 0)},
+		{"3 months ago", time.Date(2026, 7, 16, 15, 45, 30, 0, testZone)},
+		{"-1m", testNow.Add(-time.Minute)},
+		{"today", at(2026, 10, 16, 0, 0)},
+		{"yesterday", at(2026, 10, 15, 0, 0)},
+		{"Yesterday 9am", at(2026, 10, 15, 9, 0)},
+		{"monday 14:30", at(2026, 10, 12, 14, 30)},
+		{"fri", at(2026, 10, 16, 0, 0)},
+		{"sat noon", at(2026, 10, 10, 12, 0)},
+		{"7:15", at(2026, 10, 16, 7, 15)},
+		{"11pm", at(2026, 10, 16, 23, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseTime(tt.input, testNow)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseTime(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTimeErrors(t *testing.T) {
+	for _, input := range []string{"", "  ", "tomorrow", "3 fortnights ago", "-3", "yesterday 25:00", "2026-13-01"} {
+		t.Run(input, func(t *testing.T) {
+			if got, err := ParseTime(input, testNow); err == nil {
+				t.Errorf("ParseTime(%q) = %v, want an error", input, got)
+			}
+		})
+	}
+}
+
+func TestParseUntil(t *testing.T) {
+	tests := []struct {
+		input string
+		want  time.Time
+	}{
+		// whole days are included
+		{"2026-10-16", at(2026, 10, 17, 0, 0)},
+		{"yesterday", at(2026, 10, 16, 0, 0)},
+		{"yesterday 9am", at(2026, 10, 15, 9, 0)},
+		{"-3h", testNow.Add(-3 * time.Hour)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseUntil(tt.input, testNow)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseUntil(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePastTime(t *testing.T) {
+	tests := []struct {
+		input  string
+		future bool
+	}{
+		{"now", false},
+		{"-1h", false},
+		{"today", false},
+		{"3pm", false},
+		{"4pm", true},
+		{"2026-10-17", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := ParsePastTime(tt.input, testNow)
+			if future := errors.Is(err, ErrFutureTimestamp); future != tt.future {
+				t.Errorf("ParsePastTime(%q) = %v, want future %v", tt.input, err, tt.future)
+			}
+			if err != nil && !tt.future {
+				t.Errorf("ParsePastTime(%q) = %v", tt.input, err)
+			}
+		})
+	}
+}
+
+func TestParseClock(t *testing.T) {
+	tests := []struct {
+		input        string
+		hour, minute int
+		invalid      bool
+	}{
+		{input: "9", hour: 9},
+		{input: "09:05", hour: 9, minute: 5},
+		{input: "23:59", hour: 23, minute: 59},
+		{input: "9am", hour: 9},
+		{input: "9:30 pm", hour: 21, minute: 30},
+		{input: "12am", hour: 0},
+		{input: "12pm", hour: 12},
+		{input: "noon", hour: 12},
+		{input: "midnight", hour: 0},
+		{input: "24:00", invalid: true},
+		{input: "9:60", invalid: true},
+		{input: "13pm", invalid: true},
+		{input: "0am", invalid: true},
+		{input: "9.30", invalid: true},
+		{input: "", invalid: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			hour, minute, err := parseClock(tt.input)
+			if tt.invalid {
+				if err == nil {
+					t.Errorf("parseClock(%q) = %d:%02d, want an error", tt.input, hour, minute)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hour != tt.hour || minute != tt.minute {
+				t.Errorf("parseClock(%q) = %d:%02d, want %d:%02d", tt.input, hour, minute, tt.hour, tt.minute)
+			}
+		})
+	}
+}
@@ -2,173 +2,368 @@ package internal
 
 import (
 	"database/sql"
-	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
-	"path"
-	"strings"
+	"slices"
+	"time"
 
 	_ "modernc.org/sqlite"
 )
 
-//go:embed schema.sql
-var schemaFS embed.FS
-
 var db *sql.DB
 
+// InitDB opens the database and brings its schema up to date.
 func InitDB() error {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
+	if err := OpenDB(); err != nil {
+		return err
+	}
+
+	if _, err := Migrate(); err != nil {
+		var tooNew *DatabaseTooNewError
+		if errors.As(err, &tooNew) {
+			return err
+		}
+		return fmt.Errorf("failed to migrate database.\ndid you run moodgit init?\n%w", err)
+	}
+
+	if err := loadMoods(); err != nil {
+		return fmt.Errorf("failed to load moods: %w", err)
 	}
 
-	dbPath := path.Join(homeDir, ".moodgit", "moodgit.db")
-	db, err = sql.Open("sqlite", dbPath)
+	return nil
+}
+
+// OpenDB opens the database without applying any migrations.
+func OpenDB() error {
+	return openDB("")
+}
+
+// OpenDBReadOnly opens the database without migrating it and loads the
+// moods. shell completion uses it, so pressing tab never changes, or
+// creates, a database.
+func OpenDBReadOnly() error {
+	if err := InspectDB(); err != nil {
+		return err
+	}
+	return loadMoods()
+}
 
+// InspectDB opens the database read-only and as it is, whatever version its
+// schema has. a missing database is an error instead of being created.
+func InspectDB() error {
+	dbPath, err := DBPath()
 	if err != nil {
-		return fmt.Errorf("failed to open database.\ndid you run moodgit init?\n%w", err)
+		return err
 	}
 
-	if err := migrate(); err != nil {
-		return fmt.Errorf("failed to migrate database.\ndid you run moodgit init?\n%w", err)
+	if _, err := os.Stat(dbPath); err != nil {
+		return fmt.Errorf("failed to open database.\ndid you run moodgit init?\n%w", err)
 	}
 
-	return nil
+	return openDB("&mode=ro")
 }
 
-func migrate() error {
-	schemaBytes, err := schemaFS.ReadFile("schema.sql")
+func openDB(params string) error {
+	dbPath, err := DBPath()
 	if err != nil {
 		return err
 	}
 
-	_, err = db.Exec(string(schemaBytes))
-	return err
+	// entries reference the moods table, sqlite only checks foreign keys
+	// when asked to on every connection. the path is escaped so a ? or # in
+	// it isn't taken for the query.
+	db, err = sql.Open("sqlite", "file:"+url.PathEscape(dbPath)+"?_pragma=foreign_keys(1)"+params)
+
+	if err != nil {
+		return fmt.Errorf("failed to open database.\ndid you run moodgit init?\n%w", err)
+	}
+
+	return nil
 }
 
-func getFilteredHistory(pageSize int, offset int, filter string, search string) ([]Entry, int, error) {
-	var countQuery strings.Builder
-	var dataQuery strings.Builder
-	var countArgs []interface{}
-	var dataArgs []interface{}
+const (
+	entryColumns = "id, intensity, mood, message, " + entryTagsColumn + ", created_at, updated_at, hash, parent_hash, tz"
 
-	countQuery.WriteString(`
-		SELECT COUNT(*)
-		FROM entries 
-		WHERE 1=1`)
+	// the tags of an entry as a json array, in the order they were given
+	entryTagsColumn = `(SELECT json_group_array(tags.name ORDER BY entry_tags.position)
+		FROM entry_tags JOIN tags ON tags.id = entry_tags.tag_id
+		WHERE entry_tags.entry_id = entries.id)`
 
-	dataQuery.WriteString(`
-		SELECT id, intensity, mood, message, tags, created_at, updated_at
-		FROM entries 
-		WHERE 1=1`)
+	// matches the layout sqlite uses for CURRENT_TIMESTAMP
+	sqliteTimeLayout = "2006-01-02 15:04:05"
+)
 
-	if filter != "all" && filter != "" {
-		countQuery.WriteString(" AND mood = ?")
-		dataQuery.WriteString(" AND mood = ?")
-		countArgs = append(countArgs, filter)
-		dataArgs = append(dataArgs, filter)
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanEntry(row rowScanner) (Entry, error) {
+	var entry Entry
+	var tagsJSON string
+	if err := row.Scan(&entry.ID, &entry.Intensity, &entry.Mood, &entry.Message, &tagsJSON, &entry.CreatedAt, &entry.UpdatedAt, &entry.Hash, &entry.ParentHash, &entry.TimeZone); err != nil {
+		return entry, err
+	}
+
+	if err := json.Unmarshal([]byte(tagsJSON), &entry.Tags); err != nil {
+		return entry, err
 	}
 
-	if search != "" {
-		countQuery.WriteString(" AND (message LIKE ? OR tags LIKE ?)")
-		dataQuery.WriteString(" AND (message LIKE ? OR tags LIKE ?)")
-		searchPattern := "%" + search + "%"
-		countArgs = append(countArgs, searchPattern, searchPattern)
-		dataArgs = append(dataArgs, searchPattern, searchPattern)
+	return entry, nil
+}
+
+func getFilteredHistory(base HistoryFilter, pageSize int, offset int, filter string, search string) ([]Entry, int, error) {
+	f := base
+	f.Search = search
+	f.Limit = pageSize
+	f.Offset = offset
+	// the mood filter narrows the moods of the base filter, it never widens
+	// them
+	if filter != "all" && filter != "" {
+		if len(base.Moods) > 0 && !slices.Contains(base.Moods, filter) {
+			return nil, 0, nil
+		}
+		f.Moods = []Mood{filter}
 	}
 
-	var totalCount int
-	err := db.QueryRow(countQuery.String(), countArgs...).Scan(&totalCount)
+	totalCount, err := CountEntries(f)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	dataQuery.WriteString(" ORDER BY created_at DESC LIMIT ? OFFSET ?")
-	dataArgs = append(dataArgs, pageSize, offset)
-
-	rows, err := db.Query(dataQuery.String(), dataArgs...)
+	entries, err := QueryEntries(f)
 	if err != nil {
 		return nil, 0, err
 	}
-	defer rows.Close()
 
-	var entries []Entry
-	for rows.Next() {
-		var entry Entry
-		var tagsJSON string
-		if err := rows.Scan(&entry.ID, &entry.Intensity, &entry.Mood, &entry.Message, &tagsJSON, &entry.CreatedAt, &entry.UpdatedAt); err != nil {
-			return nil, 0, err
-		}
+	return entries, totalCount, nil
+}
 
-		if err := json.Unmarshal([]byte(tagsJSON), &entry.Tags); err != nil {
-			return nil, 0, err
-		}
+// AddEntry appends the entry to the history and returns it as stored, a
+// zero CreatedAt means now.
+func AddEntry(entry Entry) (Entry, error) {
+	if err := entry.validate(); err != nil {
+		return Entry{}, err
+	}
 
-		entries = append(entries, entry)
+	tx, err := db.Begin()
+	if err != nil {
+		return Entry{}, err
 	}
+	defer tx.Rollback()
 
-	if err := rows.Err(); err != nil {
-		return nil, 0, err
+	entry, err = insertEntry(tx, entry)
+	if err != nil {
+		return Entry{}, err
 	}
 
-	return entries, totalCount, nil
+	return entry, tx.Commit()
 }
 
-func AddEntry(entry Entry) error {
-	tagsJSON, err := json.Marshal(entry.Tags)
+// insertEntry appends a validated entry to the end of the hash chain. a zero
+// CreatedAt means now and a zero UpdatedAt means CreatedAt, so a backdated
+// entry doesn't look amended.
+func insertEntry(tx *sql.Tx, entry Entry) (Entry, error) {
+	err := tx.QueryRow(`SELECT hash FROM entries ORDER BY id DESC LIMIT 1`).Scan(&entry.ParentHash)
+	if err != nil && err != sql.ErrNoRows {
+		return Entry{}, err
+	}
+
+	now := time.Now()
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = now
+	} else if entry.CreatedAt.After(now) {
+		return Entry{}, ErrFutureTimestamp
+	}
+
+	if entry.UpdatedAt.IsZero() {
+		entry.UpdatedAt = entry.CreatedAt
+	}
+
+	entry.CreatedAt = entry.CreatedAt.UTC().Truncate(time.Second)
+	entry.UpdatedAt = entry.UpdatedAt.UTC().Truncate(time.Second)
+	if entry.TimeZone == "" {
+		entry.TimeZone = localZoneName()
+	}
+	entry.Hash = entry.computeHash()
+
+	res, err := tx.Exec(`
+		INSERT INTO entries (intensity, mood, message, created_at, updated_at, hash, parent_hash, tz) 
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		entry.Intensity, entry.Mood, entry.Message,
+		entry.CreatedAt.Format(sqliteTimeLayout), entry.UpdatedAt.Format(sqliteTimeLayout),
+		entry.Hash, entry.ParentHash, entry.TimeZone)
 	if err != nil {
-		return err
+		return Entry{}, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return Entry{}, err
+	}
+	entry.ID = int(id)
+
+	if err := setEntryTags(tx, entry.ID, entry.Tags); err != nil {
+		return Entry{}, err
+	}
+
+	return entry, nil
+}
+
+// EntryPatch describes a partial update, nil fields are left untouched.
+type EntryPatch struct {
+	Intensity *int8
+	Mood      *Mood
+	Message   *string
+	Tags      *[]string
+}
+
+func (p EntryPatch) IsEmpty() bool {
+	return p.Intensity == nil && p.Mood == nil && p.Message == nil && p.Tags == nil
+}
+
+// Changes drops the fields of the patch that wouldn't change the entry.
+func (p EntryPatch) Changes(entry Entry) EntryPatch {
+	if p.Intensity != nil && *p.Intensity == entry.Intensity {
+		p.Intensity = nil
 	}
+	if p.Mood != nil {
+		if mood, err := ResolveMood(*p.Mood); err == nil && mood == entry.Mood {
+			p.Mood = nil
+		}
+	}
+	if p.Message != nil && *p.Message == entry.Message {
+		p.Message = nil
+	}
+	if p.Tags != nil {
+		if tags, err := normalizeTags(*p.Tags); err == nil && slices.Equal(tags, entry.Tags) {
+			p.Tags = nil
+		}
+	}
+	return p
+}
 
-	_, err = db.Exec(`
-		INSERT INTO entries (intensity, mood, message, tags) 
-		VALUES (?, ?, ?, ?)`,
-		entry.Intensity, entry.Mood, entry.Message, string(tagsJSON))
-	return err
+func (p EntryPatch) apply(entry *Entry) {
+	if p.Intensity != nil {
+		entry.Intensity = *p.Intensity
+	}
+	if p.Mood != nil {
+		entry.Mood = *p.Mood
+	}
+	if p.Message != nil {
+		entry.Message = *p.Message
+	}
+	if p.Tags != nil {
+		entry.Tags = *p.Tags
+	}
 }
 
-func AmendLastEntry(entry Entry) error {
-	tagsJSON, err := json.Marshal(entry.Tags)
+func AmendLastEntry(patch EntryPatch) (Entry, error) {
+	var id int
+	if err := db.QueryRow(`SELECT id FROM entries ORDER BY created_at DESC, id DESC LIMIT 1`).Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			return Entry{}, ErrEntryNotFound
+		}
+		return Entry{}, err
+	}
+
+	return UpdateEntry(id, patch)
+}
+
+// UpdateEntry applies the patch to the entry with the given id and rewrites
+// the hash chain from there on.
+func UpdateEntry(id int, patch EntryPatch) (Entry, error) {
+	tx, err := db.Begin()
 	if err != nil {
-		return err
+		return Entry{}, err
+	}
+	defer tx.Rollback()
+
+	entry, err := scanEntry(tx.QueryRow(`SELECT `+entryColumns+` FROM entries WHERE id = ?`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return Entry{}, fmt.Errorf("%w: %d", ErrEntryNotFound, id)
+		}
+		return Entry{}, err
+	}
+
+	if err := recordRevision(tx, OpUpdate, id, &entry, 0); err != nil {
+		return Entry{}, err
+	}
+
+	patch.apply(&entry)
+	if err := entry.validate(); err != nil {
+		return Entry{}, err
 	}
 
-	_, err = db.Exec(`
+	_, err = tx.Exec(`
 		UPDATE entries 
-		SET intensity = ?, mood = ?, message = ?, tags = ? 
-		WHERE id = (SELECT id FROM entries ORDER BY created_at DESC LIMIT 1)`,
-		entry.Intensity, entry.Mood, entry.Message, string(tagsJSON))
-	return err
+		SET intensity = ?, mood = ?, message = ? 
+		WHERE id = ?`,
+		entry.Intensity, entry.Mood, entry.Message, id)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	if err := setEntryTags(tx, id, entry.Tags); err != nil {
+		return Entry{}, err
+	}
+
+	if err := rehashFrom(tx, id); err != nil {
+		return Entry{}, err
+	}
+
+	entry, err = scanEntry(tx.QueryRow(`SELECT `+entryColumns+` FROM entries WHERE id = ?`, id))
+	if err != nil {
+		return Entry{}, err
+	}
+
+	return entry, tx.Commit()
 }
 
-func GetHistory(limit uint16) error {
-	rows, err := db.Query(
-		`SELECT id, intensity, mood, message, tags, created_at, updated_at
-		FROM entries ORDER BY created_at DESC LIMIT ?`, limit)
+// DeleteEntries removes all given entries or none of them, their last state
+// is kept in the reflog.
+func DeleteEntries(ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
 
+	tx, err := db.Begin()
 	if err != nil {
 		return err
 	}
-	defer rows.Close()
+	defer tx.Rollback()
+
+	minID := ids[0]
+	for _, id := range ids {
+		entry, err := scanEntry(tx.QueryRow(`SELECT `+entryColumns+` FROM entries WHERE id = ?`, id))
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("%w: %d", ErrEntryNotFound, id)
+			}
+			return err
+		}
 
-	for rows.Next() {
-		var entry Entry
-		var tagsJSON string
-		if err := rows.Scan(&entry.ID, &entry.Intensity, &entry.Mood, &entry.Message, &tagsJSON, &entry.CreatedAt, &entry.UpdatedAt); err != nil {
+		if err := recordRevision(tx, OpDelete, id, &entry, 0); err != nil {
 			return err
 		}
 
-		if err := json.Unmarshal([]byte(tagsJSON), &entry.Tags); err != nil {
+		if _, err := tx.Exec(`DELETE FROM entries WHERE id = ?`, id); err != nil {
 			return err
 		}
 
-		fmt.Println(entry.String())
+		minID = min(minID, id)
 	}
 
-	if err := rows.Err(); err != nil {
+	if err := pruneTags(tx); err != nil {
 		return err
 	}
 
-	return nil
+	if err := rehashFrom(tx, minID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
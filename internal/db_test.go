@@ -0,0 +1,155 @@
+package internal
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// openTestDB points the package at a fresh, fully migrated database in a
+// temporary repository.
+func openTestDB(t *testing.T) {
+	t.Helper()
+	openEmptyDB(t)
+
+	if err := InitDB(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// openEmptyDB opens a database without any schema in a temporary repository.
+func openEmptyDB(t *testing.T) {
+	t.Helper()
+	SetRepo(t.TempDir())
+
+	if err := OpenDB(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		db.Close()
+		SetRepo("")
+	})
+}
+
+func TestAddEntryTimes(t *testing.T) {
+	openTestDB(t)
+
+	backdated := time.Now().Add(-48 * time.Hour).Truncate(time.Second)
+	amended := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	tests := []struct {
+		name      string
+		createdAt time.Time
+		updatedAt time.Time
+		want      time.Time // updated_at, zero means now
+	}{
+		{"new", time.Time{}, time.Time{}, time.Time{}},
+		{"backdated", backdated, time.Time{}, backdated},
+		{"backdated and amended", backdated, amended, amended},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := time.Now().Truncate(time.Second)
+			added, err := AddEntry(Entry{Mood: MoodCalm, Intensity: 4, CreatedAt: tt.createdAt, UpdatedAt: tt.updatedAt})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			entry, err := getEntryByID(added.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.want.IsZero() {
+				if entry.CreatedAt.Before(before) || !entry.UpdatedAt.Equal(entry.CreatedAt) {
+					t.Errorf("new entry was created at %v and updated at %v, want both now", entry.CreatedAt, entry.UpdatedAt)
+				}
+				return
+			}
+
+			if !entry.CreatedAt.Equal(tt.createdAt) || !entry.UpdatedAt.Equal(tt.want) {
+				t.Errorf("entry was created at %v and updated at %v, want %v and %v", entry.CreatedAt, entry.UpdatedAt, tt.createdAt, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetFilteredHistory(t *testing.T) {
+	openTestDB(t)
+	addFilterFixture(t)
+
+	tests := []struct {
+		name   string
+		base   HistoryFilter
+		offset int
+		filter string
+		search string
+		want   []int
+		total  int
+	}{
+		{name: "all", filter: "all", want: []int{5, 4, 3}, total: 5},
+		{name: "second page", offset: 3, filter: "all", want: []int{2, 1}, total: 5},
+		{name: "mood", filter: MoodHappy, want: []int{4, 1}, total: 2},
+		{name: "base moods", base: HistoryFilter{Moods: []Mood{MoodHappy, MoodSad}}, filter: "all", want: []int{4, 2, 1}, total: 3},
+		// the mood filter narrows the moods of the base filter, it never widens them
+		{name: "narrowed", base: HistoryFilter{Moods: []Mood{MoodHappy, MoodSad}}, filter: MoodSad, want: []int{2}, total: 1},
+		{name: "not widened", base: HistoryFilter{Moods: []Mood{MoodHappy, MoodSad}}, filter: MoodCalm, want: []int{}, total: 0},
+		{name: "base tags", base: HistoryFilter{Tags: []string{"work"}}, filter: MoodHappy, want: []int{4, 1}, total: 2},
+		{name: "search", base: HistoryFilter{Tags: []string{"work"}}, filter: MoodHappy, search: "lunch", want: []int{4}, total: 1},
+		{name: "search a tag", filter: "all", search: "home", want: []int{3, 2}, total: 2},
+		{name: "base dates", base: HistoryFilter{Since: time.Date(2025, 10, 10, 0, 0, 0, 0, time.UTC)}, filter: MoodHappy, want: []int{4}, total: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, total, err := getFilteredHistory(tt.base, 3, tt.offset, tt.filter, tt.search)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := entryIDs(entries); !slices.Equal(got, tt.want) || total != tt.total {
+				t.Errorf("found %v of %d, want %v of %d", got, total, tt.want, tt.total)
+			}
+		})
+	}
+}
+
+func TestAddEntryTimeZone(t *testing.T) {
+	openTestDB(t)
+	t.Setenv("TZ", "America/New_York")
+
+	location := displayLocation
+	t.Cleanup(func() { displayLocation = location })
+
+	tests := []struct {
+		name    string
+		display string // --tz
+		zone    string
+		want    string
+	}{
+		{name: "local", want: "America/New_York"},
+		// --tz only changes the display
+		{name: "display override", display: "Asia/Tokyo", want: "America/New_York"},
+		{name: "given", display: "Asia/Tokyo", zone: "Europe/Berlin", want: "Europe/Berlin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			displayLocation = time.Local
+			if tt.display != "" {
+				if err := SetTimeZone(tt.display); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			added, err := AddEntry(Entry{Mood: MoodCalm, Intensity: 4, TimeZone: tt.zone})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if added.TimeZone != tt.want {
+				t.Errorf("the entry was logged in %q, want %q", added.TimeZone, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -10,6 +11,8 @@ import (
 
 type Mood = string
 
+// the moods every journal starts with, more can be defined in the moods
+// table with `moodgit mood add`
 const (
 	MoodHappy    Mood = "happy"
 	MoodSad      Mood = "sad"
@@ -23,13 +26,58 @@ const (
 )
 
 type Entry struct {
-	ID        int       `json:"id" db:"id"`
-	Intensity int8      `json:"intensity" db:"intensity"`
-	Mood      Mood      `json:"mood" db:"mood"`
-	Message   string    `json:"message" db:"message"`
-	Tags      []string  `json:"tags" db:"tags"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID         int       `json:"id" db:"id"`
+	Hash       string    `json:"hash" db:"hash"`
+	ParentHash string    `json:"parent" db:"parent_hash"`
+	Intensity  int8      `json:"intensity" db:"intensity"`
+	Mood       Mood      `json:"mood" db:"mood"`
+	Message    string    `json:"message" db:"message"`
+	Tags       []string  `json:"tags" db:"tags"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+	TimeZone   string    `json:"tz" db:"tz"`
+}
+
+// entryJSON is the stable json schema of an entry, see the readme.
+type entryJSON struct {
+	ID        int      `json:"id"`
+	Hash      string   `json:"hash"`
+	Parent    string   `json:"parent"`
+	CreatedAt string   `json:"created_at"`
+	UpdatedAt string   `json:"updated_at"`
+	TimeZone  string   `json:"tz"`
+	Mood      Mood     `json:"mood"`
+	Intensity int8     `json:"intensity"`
+	Message   string   `json:"message"`
+	Tags      []string `json:"tags"`
+	Score     float64  `json:"score"`
+	Arousal   float64  `json:"arousal"`
+}
+
+func (e Entry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.schema())
+}
+
+func (e Entry) schema() entryJSON {
+	tags := e.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return entryJSON{
+		ID:        e.ID,
+		Hash:      e.Hash,
+		Parent:    e.ParentHash,
+		CreatedAt: localTime(e.CreatedAt).Format(time.RFC3339),
+		UpdatedAt: localTime(e.UpdatedAt).Format(time.RFC3339),
+		TimeZone:  e.TimeZone,
+		Mood:      e.Mood,
+		Intensity: e.Intensity,
+		Message:   e.Message,
+		Tags:      tags,
+		Score:     e.Score(),
+		Arousal:   e.Arousal(),
+	}
 }
 
 func (e *Entry) String() string {
@@ -39,7 +87,8 @@ func (e *Entry) String() string {
 	coloredMood := intensityStyle.Sprint(string(e.Mood))
 
 	parts := []string{
-		e.CreatedAt.Format("2006/01/02 15:04"),
+		color.Yellow.Sprint(e.ShortHash()),
+		localTime(e.CreatedAt).Format("2006/01/02 15:04"),
 		fmt.Sprintf("%02d/10 %s", e.Intensity, coloredMood),
 	}
 
@@ -54,44 +103,78 @@ func (e *Entry) String() string {
 	return strings.Join(parts, " | ")
 }
 
-func (e *Entry) getMoodColor() color.Color {
-	switch e.Mood {
-	case MoodHappy:
-		return color.Green
-	case MoodSad:
-		return color.Blue
-	case MoodAngry:
-		return color.Red
-	case MoodAnxious:
-		return color.Yellow
-	case MoodExcited:
-		return color.Magenta
-	case MoodCalm:
-		return color.Cyan
-	case MoodStressed:
-		return color.LightRed
-	case MoodTired:
-		return color.Gray
-	case MoodNeutral:
-		return color.White
-	default:
-		return color.White
+// Detail renders the entry in full, the way `moodgit show` prints it.
+func (e *Entry) Detail() string {
+	moodColor := e.getMoodColor()
+	intensityStyle := e.getIntensityStyle(moodColor)
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s\n", color.Yellow.Sprintf("entry %s", e.Hash))
+	fmt.Fprintf(&b, "id:        %d\n", e.ID)
+	if e.ParentHash != "" {
+		fmt.Fprintf(&b, "parent:    %s\n", abbrev(e.ParentHash))
+	}
+	def, _ := LookupMood(e.Mood)
+	fmt.Fprintf(&b, "mood:      %s\n", intensityStyle.Sprint(def.Label()))
+	fmt.Fprintf(&b, "intensity: %s %02d/10\n", e.intensityBar(moodColor), e.Intensity)
+	fmt.Fprintf(&b, "score:     %s (%s)\n", formatScore(e.Score()), describeAffect(e.Score(), e.Arousal()))
+	if len(e.Tags) > 0 {
+		fmt.Fprintf(&b, "tags:      %s\n", strings.Join(e.Tags, ", "))
 	}
+	fmt.Fprintf(&b, "created:   %s%s\n", localTime(e.CreatedAt).Format("2006/01/02 15:04:05 MST"), e.originZone())
+	if e.UpdatedAt.After(e.CreatedAt) {
+		fmt.Fprintf(&b, "amended:   %s\n", localTime(e.UpdatedAt).Format("2006/01/02 15:04:05 MST"))
+	}
+
+	if e.Message != "" {
+		b.WriteString("\n")
+		for _, line := range strings.Split(e.Message, "\n") {
+			fmt.Fprintf(&b, "    %s\n", line)
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// originZone notes where an entry was logged when that differs from the
+// zone it is displayed in.
+func (e *Entry) originZone() string {
+	if e.TimeZone == "" || e.TimeZone == zoneName() {
+		return ""
+	}
+
+	loc, err := time.LoadLocation(e.TimeZone)
+	if err != nil {
+		return fmt.Sprintf(" (logged in %s)", e.TimeZone)
+	}
+
+	return fmt.Sprintf(" (logged in %s, %s)", e.TimeZone, e.CreatedAt.In(loc).Format("15:04 MST"))
+}
+
+func (e *Entry) intensityBar(moodColor moodColor) string {
+	filled := int(e.Intensity)
+	return moodColor.Sprint(strings.Repeat("█", filled)) + color.Gray.Sprint(strings.Repeat("░", 10-filled))
+}
+
+func (e *Entry) getMoodColor() moodColor {
+	def, _ := LookupMood(e.Mood)
+	return def.style()
 }
 
-func (e *Entry) getIntensityStyle(baseColor color.Color) color.Style {
+func (e *Entry) getIntensityStyle(baseColor moodColor) moodColor {
 	intensity := e.Intensity
 
 	switch {
 	case intensity >= 8:
-		return color.New(baseColor, color.OpBold, color.OpItalic)
+		return baseColor.with(color.OpBold, color.OpItalic)
 	case intensity >= 6:
-		return color.New(baseColor, color.OpBold)
+		return baseColor.with(color.OpBold)
 	case intensity >= 4:
-		return color.New(baseColor)
+		return baseColor
 	case intensity >= 2:
-		return color.New(baseColor, color.OpFuzzy)
+		return baseColor.with(color.OpFuzzy)
 	default:
-		return color.New(baseColor, color.OpFuzzy, color.OpItalic)
+		return baseColor.with(color.OpFuzzy, color.OpItalic)
 	}
 }
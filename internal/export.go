@@ -0,0 +1,184 @@
+package internal
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ExportJSON     = "json"
+	ExportNDJSON   = "ndjson"
+	ExportCSV      = "csv"
+	ExportMarkdown = "markdown"
+)
+
+// ExportFormats lists the formats Export understands.
+var ExportFormats = []string{ExportJSON, ExportNDJSON, ExportCSV, ExportMarkdown}
+
+// CSVColumns is the header of a csv export, the fields match the json schema.
+var CSVColumns = []string{"id", "hash", "parent", "created_at", "updated_at", "tz", "mood", "intensity", "message", "tags", "score", "arousal"}
+
+// entryWriter writes entries one at a time, so exports never hold the whole
+// journal in memory.
+type entryWriter interface {
+	begin() error
+	write(e Entry) error
+	end() error
+}
+
+// Export writes the entries matching the filter to w, oldest first, and
+// returns how many were written.
+func Export(w io.Writer, format string, f HistoryFilter) (int, error) {
+	var ew entryWriter
+	switch format {
+	case ExportJSON:
+		ew = &jsonWriter{w: w}
+	case ExportNDJSON:
+		ew = &ndjsonWriter{enc: json.NewEncoder(w)}
+	case ExportCSV:
+		ew = &csvWriter{w: csv.NewWriter(w)}
+	case ExportMarkdown:
+		ew = &markdownWriter{w: w}
+	default:
+		return 0, fmt.Errorf("unknown export format %q, expected one of %s", format, strings.Join(ExportFormats, ", "))
+	}
+
+	if err := ew.begin(); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	err := EachEntry(f, func(e Entry) error {
+		count++
+		return ew.write(e)
+	})
+	if err != nil {
+		return count, err
+	}
+
+	return count, ew.end()
+}
+
+// jsonWriter writes a json array with one indented entry per element.
+type jsonWriter struct {
+	w     io.Writer
+	count int
+}
+
+func (j *jsonWriter) begin() error {
+	_, err := io.WriteString(j.w, "[")
+	return err
+}
+
+func (j *jsonWriter) write(e Entry) error {
+	data, err := json.MarshalIndent(e, "  ", "  ")
+	if err != nil {
+		return err
+	}
+
+	sep := ",\n  "
+	if j.count == 0 {
+		sep = "\n  "
+	}
+	j.count++
+
+	_, err = fmt.Fprintf(j.w, "%s%s", sep, data)
+	return err
+}
+
+func (j *jsonWriter) end() error {
+	closing := "\n]\n"
+	if j.count == 0 {
+		closing = "]\n"
+	}
+	_, err := io.WriteString(j.w, closing)
+	return err
+}
+
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+func (n *ndjsonWriter) begin() error        { return nil }
+func (n *ndjsonWriter) write(e Entry) error { return n.enc.Encode(e) }
+func (n *ndjsonWriter) end() error          { return nil }
+
+// csvWriter writes one row per entry with the tags joined by commas.
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvWriter) begin() error {
+	return c.w.Write(CSVColumns)
+}
+
+func (c *csvWriter) write(e Entry) error {
+	return c.w.Write([]string{
+		strconv.Itoa(e.ID),
+		e.Hash,
+		e.ParentHash,
+		localTime(e.CreatedAt).Format(time.RFC3339),
+		localTime(e.UpdatedAt).Format(time.RFC3339),
+		e.TimeZone,
+		e.Mood,
+		strconv.Itoa(int(e.Intensity)),
+		e.Message,
+		strings.Join(e.Tags, ","),
+		strconv.FormatFloat(e.Score(), 'f', -1, 64),
+		strconv.FormatFloat(e.Arousal(), 'f', -1, 64),
+	})
+}
+
+func (c *csvWriter) end() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// markdownWriter writes one section per day in your time zone, with the tags
+// as #hashtags so notes apps pick them up.
+type markdownWriter struct {
+	w   io.Writer
+	day string
+}
+
+func (m *markdownWriter) begin() error {
+	_, err := io.WriteString(m.w, "# moodgit journal\n")
+	return err
+}
+
+func (m *markdownWriter) write(e Entry) error {
+	var b strings.Builder
+	created := localTime(e.CreatedAt)
+
+	if day := created.Format(time.DateOnly); day != m.day {
+		m.day = day
+		fmt.Fprintf(&b, "\n## %s\n", created.Format("2006-01-02, Monday"))
+	}
+
+	def, _ := LookupMood(e.Mood)
+	fmt.Fprintf(&b, "\n### %s %s %d/10\n\n", created.Format("15:04"), def.Label(), e.Intensity)
+
+	if e.Message != "" {
+		b.WriteString(e.Message + "\n\n")
+	}
+
+	if len(e.Tags) > 0 {
+		hashtags := make([]string, len(e.Tags))
+		for i, tag := range e.Tags {
+			hashtags[i] = "#" + strings.Join(strings.Fields(tag), "-")
+		}
+		b.WriteString(strings.Join(hashtags, " ") + "\n\n")
+	}
+
+	fmt.Fprintf(&b, "*%s · score %s*\n", e.ShortHash(), formatScore(e.Score()))
+
+	_, err := io.WriteString(m.w, b.String())
+	return err
+}
+
+func (m *markdownWriter) end() error { return nil }
@@ -0,0 +1,208 @@
+package internal
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+var exportEntries = []Entry{
+	{
+		ID:        1,
+		Hash:      "aaaa",
+		CreatedAt: time.Date(2026, 10, 15, 21, 5, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2026, 10, 15, 21, 5, 0, 0, time.UTC),
+		TimeZone:  "Europe/Berlin",
+		Mood:      MoodSad,
+		Intensity: 4,
+		Message:   "rain, again\nand \"cold\"",
+		Tags:      []string{"weather", "late night"},
+	},
+	{
+		ID:         2,
+		Hash:       "bbbb",
+		ParentHash: "aaaa",
+		CreatedAt:  time.Date(2026, 10, 16, 7, 30, 0, 0, time.UTC),
+		UpdatedAt:  time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC),
+		Mood:       MoodHappy,
+		Intensity:  8,
+	},
+}
+
+// writeEntries runs the writer of the format over the entries.
+func writeEntries(t *testing.T, format string, entries []Entry) string {
+	t.Helper()
+
+	var b strings.Builder
+	var ew entryWriter
+	switch format {
+	case ExportJSON:
+		ew = &jsonWriter{w: &b}
+	case ExportNDJSON:
+		ew = &ndjsonWriter{enc: json.NewEncoder(&b)}
+	case ExportCSV:
+		ew = &csvWriter{w: csv.NewWriter(&b)}
+	case ExportMarkdown:
+		ew = &markdownWriter{w: &b}
+	}
+
+	if err := ew.begin(); err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if err := ew.write(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ew.end(); err != nil {
+		t.Fatal(err)
+	}
+
+	return b.String()
+}
+
+func TestCSVWriter(t *testing.T) {
+	openTestDB(t)
+	inUTC(t)
+
+	tests := []struct {
+		name    string
+		entries []Entry
+		want    string
+	}{
+		{
+			name: "empty",
+			want: strings.Join(CSVColumns, ",") + "\n",
+		},
+		{
+			name:    "quoted",
+			entries: exportEntries,
+			want: "id,hash,parent,created_at,updated_at,tz,mood,intensity,message,tags,score,arousal\n" +
+				"1,aaaa,,2026-10-15T21:05:00Z,2026-10-15T21:05:00Z,Europe/Berlin,sad,4,\"rain, again\nand \"\"cold\"\"\",\"weather,late night\",-3.2,-2\n" +
+				"2,bbbb,aaaa,2026-10-16T07:30:00Z,2026-10-16T08:00:00Z,,happy,8,,,6.4,4\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := writeEntries(t, ExportCSV, tt.entries)
+			if got != tt.want {
+				t.Errorf("csv export =\n%s\nwant\n%s", got, tt.want)
+			}
+
+			rows, err := csv.NewReader(strings.NewReader(got)).ReadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, e := range tt.entries {
+				row := rows[i+1]
+				if row[8] != e.Message || row[9] != strings.Join(e.Tags, ",") {
+					t.Errorf("row %d reads back as %q and %q, want %q and %q", i+1, row[8], row[9], e.Message, strings.Join(e.Tags, ","))
+				}
+			}
+		})
+	}
+}
+
+func TestJSONWriters(t *testing.T) {
+	openTestDB(t)
+	inUTC(t)
+
+	tests := []struct {
+		name    string
+		format  string
+		entries []Entry
+		want    string
+	}{
+		{name: "empty json", format: ExportJSON, want: "[]\n"},
+		{name: "empty ndjson", format: ExportNDJSON, want: ""},
+		{
+			name:    "ndjson",
+			format:  ExportNDJSON,
+			entries: exportEntries[1:],
+			want:    `{"id":2,"hash":"bbbb","parent":"aaaa","created_at":"2026-10-16T07:30:00Z","updated_at":"2026-10-16T08:00:00Z","tz":"","mood":"happy","intensity":8,"message":"","tags":[],"score":6.4,"arousal":4}` + "\n",
+		},
+		{
+			name:    "json",
+			format:  ExportJSON,
+			entries: exportEntries[1:],
+			want: `[
+  {
+    "id": 2,
+    "hash": "bbbb",
+    "parent": "aaaa",
+    "created_at": "2026-10-16T07:30:00Z",
+    "updated_at": "2026-10-16T08:00:00Z",
+    "tz": "",
+    "mood": "happy",
+    "intensity": 8,
+    "message": "",
+    "tags": [],
+    "score": 6.4,
+    "arousal": 4
+  }
+]
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := writeEntries(t, tt.format, tt.entries); got != tt.want {
+				t.Errorf("%s export =\n%s\nwant\n%s", tt.format, got, tt.want)
+			}
+		})
+	}
+
+	// both formats read back as the entries they were written from
+	for _, format := range []string{ExportJSON, ExportNDJSON} {
+		t.Run(format+" round trip", func(t *testing.T) {
+			var items []entryJSON
+			out := writeEntries(t, format, exportEntries)
+			if format == ExportJSON {
+				if err := json.Unmarshal([]byte(out), &items); err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+					var item entryJSON
+					if err := json.Unmarshal([]byte(line), &item); err != nil {
+						t.Fatal(err)
+					}
+					items = append(items, item)
+				}
+			}
+
+			if len(items) != len(exportEntries) {
+				t.Fatalf("read %d entries, want %d", len(items), len(exportEntries))
+			}
+			for i, e := range exportEntries {
+				if items[i].ID != e.ID || items[i].Message != e.Message || !slices.Equal(items[i].Tags, e.Tags) {
+					t.Errorf("entry %d reads back as %+v", e.ID, items[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMarkdownWriter(t *testing.T) {
+	openTestDB(t)
+	inUTC(t)
+
+	got := writeEntries(t, ExportMarkdown, exportEntries)
+	for _, want := range []string{
+		"# moodgit journal\n",
+		"\n## 2026-10-15, Thursday\n",
+		"\n### 21:05 ",
+		"rain, again\nand \"cold\"\n\n#weather #late-night\n\n*aaaa",
+		"\n## 2026-10-16, Friday\n",
+		"\n### 07:30 ",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("markdown export is missing %q:\n%s", want, got)
+		}
+	}
+}
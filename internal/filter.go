@@ -0,0 +1,181 @@
+package internal
+
+import (
+	"strings"
+	"time"
+)
+
+// HistoryFilter selects entries for log, the interactive log and everything
+// else that walks the history. zero values mean "no restriction".
+type HistoryFilter struct {
+	Since        time.Time // inclusive
+	Until        time.Time // exclusive
+	Moods        []Mood
+	Tags         []string
+	AllTags      bool // require every tag instead of any of them
+	MinIntensity *int8
+	MaxIntensity *int8
+	Grep         string // substring of the message
+	Search       string // words the message starts with (as typed) or a whole tag
+	Match        string // fts5 query over the message, see SearchEntries
+	Reverse      bool   // oldest first, applied after Limit like git log --reverse
+	Limit        int
+	Offset       int
+}
+
+// where builds the sql condition shared by every history query.
+func (f HistoryFilter) where() (string, []any) {
+	conds := []string{"1=1"}
+	var args []any
+
+	if !f.Since.IsZero() {
+		conds = append(conds, "created_at >= ?")
+		args = append(args, f.Since.UTC().Format(sqliteTimeLayout))
+	}
+
+	if !f.Until.IsZero() {
+		conds = append(conds, "created_at < ?")
+		args = append(args, f.Until.UTC().Format(sqliteTimeLayout))
+	}
+
+	if len(f.Moods) > 0 {
+		conds = append(conds, "mood IN ("+placeholders(len(f.Moods))+")")
+		for _, mood := range f.Moods {
+			args = append(args, mood)
+		}
+	}
+
+	if len(f.Tags) > 0 {
+		tagArgs := make([]any, len(f.Tags))
+		for i, tag := range f.Tags {
+			tagArgs[i] = tag
+		}
+
+		tagged := "SELECT entry_tags.tag_id FROM entry_tags JOIN tags ON tags.id = entry_tags.tag_id " +
+			"WHERE entry_tags.entry_id = entries.id AND tags.name IN (" + placeholders(len(f.Tags)) + ")"
+
+		if f.AllTags {
+			conds = append(conds, "(SELECT COUNT(*) FROM ("+tagged+")) = ?")
+			args = append(args, tagArgs...)
+			args = append(args, len(uniqueStrings(f.Tags)))
+		} else {
+			conds = append(conds, "EXISTS ("+tagged+")")
+			args = append(args, tagArgs...)
+		}
+	}
+
+	if f.MinIntensity != nil {
+		conds = append(conds, "intensity >= ?")
+		args = append(args, *f.MinIntensity)
+	}
+
+	if f.MaxIntensity != nil {
+		conds = append(conds, "intensity <= ?")
+		args = append(args, *f.MaxIntensity)
+	}
+
+	if f.Grep != "" {
+		conds = append(conds, "message LIKE ?")
+		args = append(args, "%"+f.Grep+"%")
+	}
+
+	if query := typedQuery(f.Search); query != "" {
+		// tags have to match as a whole, so "work" doesn't find "homework"
+		conds = append(conds, `(entries.id IN (SELECT rowid FROM entries_fts WHERE entries_fts MATCH ?) OR EXISTS (
+			SELECT 1 FROM entry_tags JOIN tags ON tags.id = entry_tags.tag_id
+			WHERE entry_tags.entry_id = entries.id AND tags.name = ? COLLATE NOCASE))`)
+		args = append(args, query, strings.TrimSpace(f.Search))
+	}
+
+	if f.Match != "" {
+		conds = append(conds, "entries.id IN (SELECT rowid FROM entries_fts WHERE entries_fts MATCH ?)")
+		args = append(args, f.Match)
+	}
+
+	return strings.Join(conds, " AND "), args
+}
+
+func CountEntries(f HistoryFilter) (int, error) {
+	where, args := f.where()
+
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM entries WHERE `+where, args...).Scan(&count)
+	return count, err
+}
+
+// QueryEntries returns the matching entries, newest first unless Reverse.
+func QueryEntries(f HistoryFilter) ([]Entry, error) {
+	where, args := f.where()
+	query := `SELECT ` + entryColumns + ` FROM entries WHERE ` + where + ` ORDER BY created_at DESC, id DESC`
+
+	if f.Limit > 0 {
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, f.Limit, f.Offset)
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if f.Reverse {
+		for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+			entries[i], entries[j] = entries[j], entries[i]
+		}
+	}
+
+	return entries, nil
+}
+
+// EachEntry streams the entries matching the filter to fn, oldest first,
+// without holding them all in memory. Limit, Offset and Reverse are ignored.
+func EachEntry(f HistoryFilter, fn func(Entry) error) error {
+	where, args := f.where()
+	rows, err := db.Query(`SELECT `+entryColumns+` FROM entries WHERE `+where+` ORDER BY created_at, id`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+func uniqueStrings(values []string) []string {
+	seen := map[string]bool{}
+	var unique []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
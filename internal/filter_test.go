@@ -0,0 +1,138 @@
+package internal
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// addFilterFixture adds five entries to the test database, ids 1 to 5 oldest
+// first.
+func addFilterFixture(t *testing.T) {
+	t.Helper()
+
+	day := func(d, hour int) time.Time {
+		return time.Date(2025, 10, d, hour, 0, 0, 0, time.UTC)
+	}
+
+	for _, e := range []Entry{
+		{CreatedAt: day(1, 9), Mood: MoodHappy, Intensity: 8, Message: "promotion at work", Tags: []string{"work"}},
+		{CreatedAt: day(5, 20), Mood: MoodSad, Intensity: 3, Message: "rainy day", Tags: []string{"home"}},
+		{CreatedAt: day(10, 12), Mood: MoodCalm, Intensity: 5, Message: "walk in the park", Tags: []string{"health", "home"}},
+		{CreatedAt: day(12, 8), Mood: MoodHappy, Intensity: 6, Message: "lunch with friends", Tags: []string{"friends", "work"}},
+		{CreatedAt: day(15, 22), Mood: MoodAnxious, Intensity: 7, Message: "deadline at Work", Tags: []string{"work", "stress"}},
+	} {
+		if _, err := AddEntry(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func entryIDs(entries []Entry) []int {
+	ids := []int{}
+	for _, e := range entries {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+func TestHistoryFilter(t *testing.T) {
+	openTestDB(t)
+	addFilterFixture(t)
+
+	oct := func(d int) time.Time {
+		return time.Date(2025, 10, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name   string
+		filter HistoryFilter
+		want   []int
+	}{
+		{"none", HistoryFilter{}, []int{5, 4, 3, 2, 1}},
+		{"since", HistoryFilter{Since: oct(5)}, []int{5, 4, 3, 2}},
+		{"since is inclusive", HistoryFilter{Since: oct(5).Add(20 * time.Hour)}, []int{5, 4, 3, 2}},
+		{"until is exclusive", HistoryFilter{Until: oct(10).Add(12 * time.Hour)}, []int{2, 1}},
+		{"since and until", HistoryFilter{Since: oct(5), Until: oct(13)}, []int{4, 3, 2}},
+		{"mood", HistoryFilter{Moods: []Mood{MoodHappy}}, []int{4, 1}},
+		{"moods", HistoryFilter{Moods: []Mood{MoodHappy, MoodSad}}, []int{4, 2, 1}},
+		{"tag", HistoryFilter{Tags: []string{"home"}}, []int{3, 2}},
+		{"any tag", HistoryFilter{Tags: []string{"stress", "health"}}, []int{5, 3}},
+		{"all tags", HistoryFilter{Tags: []string{"work", "friends"}, AllTags: true}, []int{4}},
+		{"all tags none", HistoryFilter{Tags: []string{"work", "home"}, AllTags: true}, []int{}},
+		{"all tags repeated", HistoryFilter{Tags: []string{"work", "work"}, AllTags: true}, []int{5, 4, 1}},
+		{"min intensity", HistoryFilter{MinIntensity: ptr(int8(7))}, []int{5, 1}},
+		{"max intensity", HistoryFilter{MaxIntensity: ptr(int8(5))}, []int{3, 2}},
+		{"intensity range", HistoryFilter{MinIntensity: ptr(int8(5)), MaxIntensity: ptr(int8(7))}, []int{5, 4, 3}},
+		{"grep ignores case", HistoryFilter{Grep: "work"}, []int{5, 1}},
+		{"search", HistoryFilter{Search: "lun"}, []int{4}},
+		{"search a tag", HistoryFilter{Search: "Home"}, []int{3, 2}},
+		{"match", HistoryFilter{Match: "park OR rainy"}, []int{3, 2}},
+		{
+			"mood, tag and date",
+			HistoryFilter{Moods: []Mood{MoodHappy}, Tags: []string{"work"}, Since: oct(5)},
+			[]int{4},
+		},
+		{
+			"moods and intensity",
+			HistoryFilter{Moods: []Mood{MoodHappy, MoodAnxious}, MinIntensity: ptr(int8(7))},
+			[]int{5, 1},
+		},
+		{
+			"tag, grep and until",
+			HistoryFilter{Tags: []string{"work"}, Grep: "at", Until: oct(15)},
+			[]int{1},
+		},
+		{"mood without entries", HistoryFilter{Moods: []Mood{MoodTired}}, []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := QueryEntries(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := entryIDs(entries); !slices.Equal(got, tt.want) {
+				t.Errorf("QueryEntries found %v, want %v", got, tt.want)
+			}
+
+			count, err := CountEntries(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != len(tt.want) {
+				t.Errorf("CountEntries = %d, want %d", count, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestHistoryFilterPages(t *testing.T) {
+	openTestDB(t)
+	addFilterFixture(t)
+
+	tests := []struct {
+		name   string
+		filter HistoryFilter
+		want   []int
+	}{
+		{"limit", HistoryFilter{Limit: 2}, []int{5, 4}},
+		{"offset", HistoryFilter{Limit: 2, Offset: 2}, []int{3, 2}},
+		{"last page", HistoryFilter{Limit: 2, Offset: 4}, []int{1}},
+		// like git log --reverse, the newest entries oldest first
+		{"reverse", HistoryFilter{Limit: 2, Reverse: true}, []int{4, 5}},
+		{"filtered page", HistoryFilter{Tags: []string{"work"}, Limit: 2, Offset: 1}, []int{4, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := QueryEntries(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := entryIDs(entries); !slices.Equal(got, tt.want) {
+				t.Errorf("QueryEntries found %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/gookit/color"
+)
+
+// Formatter renders a single entry for log output.
+type Formatter func(e *Entry) (string, error)
+
+// NewFormatter understands the pretty formats oneline, short, medium and full,
+// "format:<placeholders>" and go templates ("{{.Mood}} {{.Message}}").
+//
+// placeholders follow git log where it makes sense:
+//
+//	%H  hash              %h  short hash
+//	%P  parent hash       %p  short parent hash
+//	%e  entry id          %m  mood
+//	%i  intensity         %t  tags, comma separated
+//	%s  subject           %b  body (message without subject)
+//	%B  raw message       %z  time zone the entry was logged in
+//	%ad date              %as short date (YYYY-MM-DD)
+//	%aI strict ISO 8601   %at unix timestamp
+//	%ar relative date     %n  newline
+//	%%  a literal %
+func NewFormatter(spec string) (Formatter, error) {
+	switch spec {
+	case "", "default":
+		return func(e *Entry) (string, error) { return e.String(), nil }, nil
+	case "oneline":
+		return func(e *Entry) (string, error) { return e.oneline(), nil }, nil
+	case "short":
+		return func(e *Entry) (string, error) { return e.pretty(false), nil }, nil
+	case "medium":
+		return func(e *Entry) (string, error) { return e.pretty(true), nil }, nil
+	case "full":
+		return func(e *Entry) (string, error) { return e.Detail(), nil }, nil
+	}
+
+	if strings.Contains(spec, "{{") {
+		return templateFormatter(spec)
+	}
+
+	if format, ok := strings.CutPrefix(spec, "format:"); ok {
+		return placeholderFormatter(format), nil
+	}
+
+	return nil, fmt.Errorf("unknown pretty format %q, expected oneline, short, medium, full or format:<string>", spec)
+}
+
+func placeholderFormatter(format string) Formatter {
+	return func(e *Entry) (string, error) {
+		var b strings.Builder
+
+		for i := 0; i < len(format); i++ {
+			if format[i] != '%' || i == len(format)-1 {
+				b.WriteByte(format[i])
+				continue
+			}
+
+			value, n := e.placeholder(format[i+1:])
+			if n == 0 {
+				// unknown placeholders are printed as is, like git does
+				b.WriteByte('%')
+				continue
+			}
+
+			b.WriteString(value)
+			i += n
+		}
+
+		return b.String(), nil
+	}
+}
+
+// placeholder expands the placeholder at the start of s and returns how many
+// bytes of s it consumed.
+func (e *Entry) placeholder(s string) (string, int) {
+	if len(s) >= 2 && s[0] == 'a' {
+		local := localTime(e.CreatedAt)
+		switch s[1] {
+		case 'd':
+			return local.Format("2006/01/02 15:04"), 2
+		case 's':
+			return local.Format("2006-01-02"), 2
+		case 'I':
+			return local.Format(time.RFC3339), 2
+		case 't':
+			return strconv.FormatInt(e.CreatedAt.Unix(), 10), 2
+		case 'r':
+			return relativeTime(e.CreatedAt, time.Now()), 2
+		}
+	}
+
+	switch s[0] {
+	case 'H':
+		return e.Hash, 1
+	case 'h':
+		return e.ShortHash(), 1
+	case 'P':
+		return e.ParentHash, 1
+	case 'p':
+		return abbrev(e.ParentHash), 1
+	case 'e':
+		return strconv.Itoa(e.ID), 1
+	case 'm':
+		return e.Mood, 1
+	case 'i':
+		return strconv.Itoa(int(e.Intensity)), 1
+	case 't':
+		return strings.Join(e.Tags, ","), 1
+	case 's':
+		return e.Subject(), 1
+	case 'b':
+		return e.Body(), 1
+	case 'B':
+		return e.Message, 1
+	case 'z':
+		return e.TimeZone, 1
+	case 'n':
+		return "\n", 1
+	case '%':
+		return "%", 1
+	}
+
+	return "", 0
+}
+
+func templateFormatter(spec string) (Formatter, error) {
+	funcs := template.FuncMap{
+		"date": func(t time.Time, layout string) string { return localTime(t).Format(layout) },
+		"ago":  func(t time.Time) string { return relativeTime(t, time.Now()) },
+		"join": strings.Join,
+	}
+
+	tmpl, err := template.New("format").Funcs(funcs).Parse(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid format template: %w", err)
+	}
+
+	return func(e *Entry) (string, error) {
+		var b strings.Builder
+		if err := tmpl.Execute(&b, e); err != nil {
+			return "", err
+		}
+		return b.String(), nil
+	}, nil
+}
+
+// Subject is the first line of the message.
+func (e *Entry) Subject() string {
+	subject, _, _ := strings.Cut(e.Message, "\n")
+	return subject
+}
+
+// Body is the message without its subject line.
+func (e *Entry) Body() string {
+	_, body, _ := strings.Cut(e.Message, "\n")
+	return strings.TrimLeft(body, "\n")
+}
+
+func (e *Entry) oneline() string {
+	style := e.getIntensityStyle(e.getMoodColor())
+
+	line := fmt.Sprintf("%s %s %02d/10", color.Yellow.Sprint(e.ShortHash()), style.Sprint(e.Mood), e.Intensity)
+	if subject := e.Subject(); subject != "" {
+		line += " " + subject
+	}
+
+	return line
+}
+
+// pretty renders the short and medium formats, medium adds the date, tags
+// and the whole message.
+func (e *Entry) pretty(medium bool) string {
+	style := e.getIntensityStyle(e.getMoodColor())
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n", color.Yellow.Sprintf("entry %s", e.Hash))
+	fmt.Fprintf(&b, "Mood: %s (%02d/10)\n", style.Sprint(e.Mood), e.Intensity)
+
+	message := e.Subject()
+	if medium {
+		fmt.Fprintf(&b, "Date: %s\n", localTime(e.CreatedAt).Format("Mon Jan 2 15:04:05 2006 -0700"))
+		if len(e.Tags) > 0 {
+			fmt.Fprintf(&b, "Tags: %s\n", strings.Join(e.Tags, ", "))
+		}
+		message = e.Message
+	}
+
+	if message != "" {
+		b.WriteString("\n")
+		for _, line := range strings.Split(message, "\n") {
+			fmt.Fprintf(&b, "    %s\n", line)
+		}
+	}
+
+	return b.String()
+}
+
+func relativeTime(t time.Time, now time.Time) string {
+	d := now.Sub(t)
+
+	plural := func(n int, unit string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s ago", unit)
+		}
+		return fmt.Sprintf("%d %ss ago", n, unit)
+	}
+
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return plural(int(d.Minutes()), "minute")
+	case d < 24*time.Hour:
+		return plural(int(d.Hours()), "hour")
+	case d < 14*24*time.Hour:
+		return plural(int(d.Hours()/24), "day")
+	case d < 60*24*time.Hour:
+		return plural(int(d.Hours()/24/7), "week")
+	case d < 365*24*time.Hour:
+		return plural(int(d.Hours()/24/30), "month")
+	default:
+		return plural(int(d.Hours()/24/365), "year")
+	}
+}
@@ -0,0 +1,137 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+var formatEntry = Entry{
+	ID:         12,
+	Hash:       "3fa9c1d2e4b5a6978877665544332211",
+	ParentHash: "",
+	CreatedAt:  time.Date(2026, 10, 16, 7, 30, 0, 0, time.UTC),
+	TimeZone:   "Europe/Berlin",
+	Mood:       MoodHappy,
+	Intensity:  8,
+	Message:    "got a promotion\n\nthe team celebrated",
+	Tags:       []string{"work", "achievement"},
+}
+
+// inUTC displays times in utc for the duration of the test.
+func inUTC(t *testing.T) {
+	t.Helper()
+	location := displayLocation
+	displayLocation = time.UTC
+	t.Cleanup(func() { displayLocation = location })
+}
+
+func TestPlaceholderFormat(t *testing.T) {
+	inUTC(t)
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"%h %m %i", "3fa9c1d happy 8"},
+		{"%H", formatEntry.Hash},
+		{"[%P] %p", "[] (none)"},
+		{"#%e", "#12"},
+		{"%t", "work,achievement"},
+		{"%s|%b", "got a promotion|the team celebrated"},
+		{"%B", formatEntry.Message},
+		{"%z", "Europe/Berlin"},
+		{"%ad", "2026/10/16 07:30"},
+		{"%as,%m", "2026-10-16,happy"},
+		{"%aI", "2026-10-16T07:30:00Z"},
+		{"%at", "1792135800"},
+		{"%m%n%i", "happy\n8"},
+		{"100%%", "100%"},
+		// unknown placeholders and a trailing % are printed as is
+		{"%x %a %q", "%x %a %q"},
+		{"50%", "50%"},
+		{"no placeholders", "no placeholders"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			format, err := NewFormatter("format:" + tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			e := formatEntry
+			got, err := format(&e)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("format %q = %q, want %q", tt.format, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateFormat(t *testing.T) {
+	inUTC(t)
+
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"{{.Mood}} {{.Intensity}}", "happy 8"},
+		{"{{.Subject}}", "got a promotion"},
+		{`{{join .Tags "+"}}`, "work+achievement"},
+		{`{{date .CreatedAt "2006-01-02 15h"}}`, "2026-10-16 07h"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			format, err := NewFormatter(tt.template)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			e := formatEntry
+			got, err := format(&e)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("template %q = %q, want %q", tt.template, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewFormatterErrors(t *testing.T) {
+	for _, spec := range []string{"fancy", "{{.Mood", "format"} {
+		t.Run(spec, func(t *testing.T) {
+			if _, err := NewFormatter(spec); err == nil {
+				t.Errorf("NewFormatter(%q) succeeded, want an error", spec)
+			}
+		})
+	}
+}
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		ago  time.Duration
+		want string
+	}{
+		{10 * time.Second, "just now"},
+		{time.Minute, "1 minute ago"},
+		{5 * time.Hour, "5 hours ago"},
+		{3 * 24 * time.Hour, "3 days ago"},
+		{20 * 24 * time.Hour, "2 weeks ago"},
+		{90 * 24 * time.Hour, "3 months ago"},
+		{800 * 24 * time.Hour, "2 years ago"},
+	}
+
+	for _, tt := range tests {
+		if got := relativeTime(now.Add(-tt.ago), now); got != tt.want {
+			t.Errorf("relativeTime(%v ago) = %q, want %q", tt.ago, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,434 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gookit/color"
+)
+
+const (
+	GraphDaily  = "day"
+	GraphWeekly = "week"
+
+	GraphIntensity = "intensity"
+	GraphScore     = "score"
+
+	GraphBraille = "braille"
+	GraphBlock   = "block"
+	GraphSpark   = "spark"
+)
+
+var (
+	graphLineColor    = color.Cyan
+	graphAverageColor = color.Yellow
+	graphAxisColor    = color.Gray
+)
+
+// Graph holds the mean intensity or score per day or week, NaN where nothing
+// was logged.
+type Graph struct {
+	Metric  string
+	Buckets []time.Time
+	Mean    []float64
+	ByMood  map[Mood][]float64
+	Moods   []Mood // by number of entries, most frequent first
+}
+
+type GraphOptions struct {
+	Width         int
+	Height        int
+	ByMood        bool
+	MovingAverage int
+	Style         string
+}
+
+// GetGraph buckets the entries matching the filter between from and to (both
+// local days, inclusive) by day or by week.
+func GetGraph(f HistoryFilter, bucket, metric string, weekStart time.Weekday, from, to time.Time) (*Graph, error) {
+	value := "intensity"
+	switch metric {
+	case GraphIntensity:
+	case GraphScore:
+		value = "mood_score(mood, intensity)"
+	default:
+		return nil, fmt.Errorf("unknown metric %q, expected intensity or score", metric)
+	}
+
+	bucketStart := startOfDay
+	step := func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }
+
+	switch bucket {
+	case GraphDaily:
+	case GraphWeekly:
+		bucketStart = func(t time.Time) time.Time { return StartOfWeek(t, weekStart) }
+		step = func(t time.Time) time.Time { return t.AddDate(0, 0, 7) }
+	default:
+		return nil, fmt.Errorf("unknown bucket %q, expected day or week", bucket)
+	}
+
+	g := &Graph{Metric: metric, ByMood: map[Mood][]float64{}}
+	index := map[string]int{}
+	for t := bucketStart(from); !t.After(to); t = step(t) {
+		index[t.Format("2006-01-02")] = len(g.Buckets)
+		g.Buckets = append(g.Buckets, t)
+	}
+
+	f.Since = bucketStart(from)
+	f.Until = startOfDay(to).AddDate(0, 0, 1)
+	where, args := f.where()
+
+	rows, err := db.Query(`
+		SELECT local_date(created_at) AS day, mood, COUNT(*), SUM(`+value+`)
+		FROM entries WHERE `+where+`
+		GROUP BY day, mood`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	n := len(g.Buckets)
+	totals, counts := make([]float64, n), make([]int, n)
+	moodTotals, moodCounts := map[Mood][]float64{}, map[Mood][]int{}
+	moodEntries := map[Mood]int{}
+
+	for rows.Next() {
+		var day string
+		var mood Mood
+		var count int
+		var sum float64
+		if err := rows.Scan(&day, &mood, &count, &sum); err != nil {
+			return nil, err
+		}
+
+		t, err := time.ParseInLocation("2006-01-02", day, displayLocation)
+		if err != nil {
+			return nil, err
+		}
+
+		i, ok := index[bucketStart(t).Format("2006-01-02")]
+		if !ok {
+			continue
+		}
+
+		if moodTotals[mood] == nil {
+			moodTotals[mood], moodCounts[mood] = make([]float64, n), make([]int, n)
+			g.Moods = append(g.Moods, mood)
+		}
+
+		totals[i] += sum
+		counts[i] += count
+		moodTotals[mood][i] += sum
+		moodCounts[mood][i] += count
+		moodEntries[mood] += count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	g.Mean = means(totals, counts)
+	for mood := range moodTotals {
+		g.ByMood[mood] = means(moodTotals[mood], moodCounts[mood])
+	}
+
+	sort.SliceStable(g.Moods, func(i, j int) bool {
+		return moodEntries[g.Moods[i]] > moodEntries[g.Moods[j]]
+	})
+
+	return g, nil
+}
+
+func means(totals []float64, counts []int) []float64 {
+	values := make([]float64, len(totals))
+	for i := range totals {
+		if counts[i] == 0 {
+			values[i] = math.NaN()
+		} else {
+			values[i] = totals[i] / float64(counts[i])
+		}
+	}
+	return values
+}
+
+// MovingAverage is the trailing mean over the last window buckets, ignoring
+// buckets without entries.
+func MovingAverage(values []float64, window int) []float64 {
+	avg := make([]float64, len(values))
+	for i := range values {
+		sum, n := 0.0, 0
+		for j := max(0, i-window+1); j <= i; j++ {
+			if !math.IsNaN(values[j]) {
+				sum += values[j]
+				n++
+			}
+		}
+		if n == 0 {
+			avg[i] = math.NaN()
+		} else {
+			avg[i] = sum / float64(n)
+		}
+	}
+	return avg
+}
+
+type graphSeries struct {
+	name   string
+	color  colorPrinter
+	values []float64
+}
+
+func (g *Graph) Render(opts GraphOptions) (string, error) {
+	const axisWidth = 5 // "10 ┤"
+
+	var series []graphSeries
+	if opts.ByMood {
+		if opts.Style == GraphBlock {
+			return "", fmt.Errorf("per-mood series need the braille style")
+		}
+		for _, mood := range g.Moods {
+			entry := Entry{Mood: mood}
+			series = append(series, graphSeries{name: mood, color: entry.getMoodColor(), values: g.ByMood[mood]})
+		}
+	} else {
+		series = append(series, graphSeries{name: "mean " + g.Metric, color: graphLineColor, values: g.Mean})
+	}
+
+	if opts.MovingAverage > 1 {
+		series = append(series, graphSeries{
+			name:   fmt.Sprintf("%d-%s moving average", opts.MovingAverage, g.bucketName()),
+			color:  graphAverageColor,
+			values: MovingAverage(g.Mean, opts.MovingAverage),
+		})
+	}
+
+	if opts.Style == GraphSpark {
+		if opts.ByMood {
+			return "", fmt.Errorf("per-mood series need the braille style")
+		}
+		if opts.MovingAverage > 1 {
+			return "", fmt.Errorf("a moving average needs the braille or block style")
+		}
+		lo, hi := g.valueRange()
+		spark, _ := renderBlocks(g.Mean, nil, lo, hi, max(opts.Width, 10), 1)
+		return spark[0], nil
+	}
+
+	width := max(opts.Width-axisWidth, 10)
+	height := max(opts.Height, 3)
+
+	lo, hi := g.valueRange()
+
+	var plot []string
+	first := 0 // the first bucket drawn
+	switch opts.Style {
+	case GraphBraille, "":
+		plot = renderBraille(series, lo, hi, width, height)
+	case GraphBlock:
+		var overlay []float64
+		if len(series) > 1 {
+			overlay = series[1].values
+		}
+		plot, width = renderBlocks(series[0].values, overlay, lo, hi, width, height)
+		first = len(g.Buckets) - width
+	default:
+		return "", fmt.Errorf("unknown style %q, expected braille, block or spark", opts.Style)
+	}
+
+	var b strings.Builder
+	for row, line := range plot {
+		label := ""
+		switch row {
+		case 0:
+			label = fmt.Sprint(hi)
+		case (height - 1) / 2:
+			label = fmt.Sprint((lo + hi) / 2)
+		case height - 1:
+			label = fmt.Sprint(lo)
+		}
+		fmt.Fprintf(&b, "%3s %s%s\n", label, graphAxisColor.Sprint("┤"), line)
+	}
+
+	fmt.Fprintf(&b, "    %s\n", graphAxisColor.Sprint("└"+strings.Repeat("─", width)))
+
+	if len(g.Buckets) > 0 {
+		start := g.Buckets[first].Format("2006/01/02")
+		end := g.Buckets[len(g.Buckets)-1].Format("2006/01/02")
+		gap := max(1, width-len(start)-len(end)+1)
+		fmt.Fprintf(&b, "     %s%s%s\n", start, strings.Repeat(" ", gap), end)
+	}
+
+	var legend []string
+	for _, s := range series {
+		legend = append(legend, s.color.Sprint("━━")+" "+s.name)
+	}
+	b.WriteString("\n" + strings.Join(legend, "  "))
+
+	return b.String(), nil
+}
+
+// valueRange is the range of the y axis, the score can be negative.
+func (g *Graph) valueRange() (float64, float64) {
+	if g.Metric == GraphScore {
+		return -10, 10
+	}
+	return 0, 10
+}
+
+func (g *Graph) bucketName() string {
+	if len(g.Buckets) > 1 && g.Buckets[1].Sub(g.Buckets[0]) > 24*time.Hour+time.Hour {
+		return GraphWeekly
+	}
+	return GraphDaily
+}
+
+// braille cells hold 2x4 dots, bit layout per the unicode braille block
+var brailleDots = [4][2]rune{
+	{0x01, 0x08},
+	{0x02, 0x10},
+	{0x04, 0x20},
+	{0x40, 0x80},
+}
+
+// renderBraille draws every series as a line, later series are drawn on top.
+func renderBraille(series []graphSeries, lo, hi float64, width, height int) []string {
+	dots := make([][]rune, height)
+	colors := make([][]colorPrinter, height)
+	for i := range dots {
+		dots[i] = make([]rune, width)
+		colors[i] = make([]colorPrinter, width)
+	}
+
+	pxWidth, pxHeight := width*2, height*4
+
+	set := func(x, y int, c colorPrinter) {
+		if x < 0 || y < 0 || x >= pxWidth || y >= pxHeight {
+			return
+		}
+		dots[y/4][x/2] |= brailleDots[y%4][x%2]
+		colors[y/4][x/2] = c
+	}
+
+	for _, s := range series {
+		n := len(s.values)
+		prevX, prevY, havePrev := 0, 0, false
+
+		for i, v := range s.values {
+			if math.IsNaN(v) {
+				continue
+			}
+
+			x := 0
+			if n > 1 {
+				x = int(math.Round(float64(i) * float64(pxWidth-1) / float64(n-1)))
+			}
+			y := int(math.Round((hi - v) / (hi - lo) * float64(pxHeight-1)))
+
+			if havePrev {
+				drawLine(prevX, prevY, x, y, func(x, y int) { set(x, y, s.color) })
+			} else {
+				set(x, y, s.color)
+			}
+			prevX, prevY, havePrev = x, y, true
+		}
+	}
+
+	lines := make([]string, height)
+	for row := range dots {
+		var b strings.Builder
+		for col, d := range dots[row] {
+			if d == 0 {
+				b.WriteRune(' ')
+				continue
+			}
+			b.WriteString(colors[row][col].Sprint(string(0x2800 + d)))
+		}
+		lines[row] = b.String()
+	}
+
+	return lines
+}
+
+// drawLine plots a straight line with bresenham's algorithm.
+func drawLine(x0, y0, x1, y1 int, plot func(x, y int)) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+
+	err := dx + dy
+	for {
+		plot(x0, y0)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		if e2 := 2 * err; e2 >= dy {
+			err += dy
+			x0 += sx
+		} else {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+var blockEighths = []rune(" ▁▂▃▄▅▆▇█")
+
+// renderBlocks draws one bar per bucket, keeping the newest buckets if they
+// don't fit, and marks the overlay value with a dot above the bar.
+func renderBlocks(values, overlay []float64, lo, hi float64, width, height int) ([]string, int) {
+	if len(values) > width {
+		values = values[len(values)-width:]
+		if overlay != nil {
+			overlay = overlay[len(overlay)-width:]
+		}
+	}
+
+	lines := make([]string, height)
+	for row := 0; row < height; row++ {
+		var b strings.Builder
+		bottom := float64(height-1-row) * 8 // in eighths of a row
+
+		for i, v := range values {
+			cell := " "
+			c := colorPrinter(graphLineColor)
+
+			if !math.IsNaN(v) {
+				level := (v - lo) / (hi - lo) * float64(height*8)
+				if fill := int(math.Round(level - bottom)); fill > 0 {
+					cell = string(blockEighths[min(fill, 8)])
+				}
+			}
+
+			if overlay != nil && !math.IsNaN(overlay[i]) && cell == " " {
+				level := (overlay[i] - lo) / (hi - lo) * float64(height*8)
+				if level >= bottom && level < bottom+8 {
+					cell, c = "•", graphAverageColor
+				}
+			}
+
+			if cell == " " {
+				b.WriteString(cell)
+			} else {
+				b.WriteString(c.Sprint(cell))
+			}
+		}
+		lines[row] = b.String()
+	}
+
+	return lines, len(values)
+}
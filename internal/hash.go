@@ -0,0 +1,145 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const shortHashLen = 7
+
+// computeHash hashes the entry content together with its parent hash, so
+// every entry pins the whole history before it, just like a git commit.
+func (e *Entry) computeHash() string {
+	tags := append([]string{}, e.Tags...)
+	sort.Strings(tags)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "parent %s\n", e.ParentHash)
+	fmt.Fprintf(&b, "time %s\n", e.CreatedAt.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "mood %s\n", e.Mood)
+	fmt.Fprintf(&b, "intensity %d\n", e.Intensity)
+	fmt.Fprintf(&b, "tags %s\n", strings.Join(tags, ","))
+	fmt.Fprintf(&b, "\n%s", e.Message)
+
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+func (e *Entry) ShortHash() string {
+	if len(e.Hash) < shortHashLen {
+		return e.Hash
+	}
+	return e.Hash[:shortHashLen]
+}
+
+// rehashFrom rewrites the hash chain starting at the entry with the given id,
+// which is needed whenever an entry is changed or removed.
+func rehashFrom(tx *sql.Tx, fromID int) error {
+	var parent string
+	err := tx.QueryRow(`SELECT hash FROM entries WHERE id < ? ORDER BY id DESC LIMIT 1`, fromID).Scan(&parent)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	rows, err := tx.Query(`SELECT `+entryColumns+` FROM entries WHERE id >= ? ORDER BY id`, fromID)
+	if err != nil {
+		return err
+	}
+
+	var entries []Entry
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		entries = append(entries, entry)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		oldHash, oldParent := entry.Hash, entry.ParentHash
+		entry.ParentHash = parent
+		entry.Hash = entry.computeHash()
+
+		if entry.Hash != oldHash || entry.ParentHash != oldParent {
+			if _, err := tx.Exec(`UPDATE entries SET hash = ?, parent_hash = ? WHERE id = ?`, entry.Hash, entry.ParentHash, entry.ID); err != nil {
+				return err
+			}
+		}
+
+		parent = entry.Hash
+	}
+
+	return nil
+}
+
+type FsckProblem struct {
+	Entry  Entry
+	Kind   string
+	Detail string
+}
+
+const (
+	FsckTampered  = "tampered"
+	FsckReordered = "reordered"
+)
+
+// Fsck walks the whole history and verifies every hash and parent link.
+func Fsck() (int, []FsckProblem, error) {
+	rows, err := db.Query(`SELECT ` + entryColumns + ` FROM entries ORDER BY id`)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+
+	var problems []FsckProblem
+	var parent string
+	checked := 0
+
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return checked, nil, err
+		}
+		checked++
+
+		if entry.ParentHash != parent {
+			problems = append(problems, FsckProblem{
+				Entry:  entry,
+				Kind:   FsckReordered,
+				Detail: fmt.Sprintf("parent is %s, expected %s", abbrev(entry.ParentHash), abbrev(parent)),
+			})
+		}
+
+		if want := entry.computeHash(); entry.Hash != want {
+			problems = append(problems, FsckProblem{
+				Entry:  entry,
+				Kind:   FsckTampered,
+				Detail: fmt.Sprintf("content hashes to %s, stored %s", abbrev(want), abbrev(entry.Hash)),
+			})
+		}
+
+		parent = entry.Hash
+	}
+
+	return checked, problems, rows.Err()
+}
+
+func abbrev(hash string) string {
+	if hash == "" {
+		return "(none)"
+	}
+	if len(hash) > shortHashLen {
+		return hash[:shortHashLen]
+	}
+	return hash
+}
@@ -0,0 +1,100 @@
+package internal
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestComputeHash(t *testing.T) {
+	base := Entry{
+		ParentHash: "abc",
+		CreatedAt:  time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC),
+		UpdatedAt:  time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC),
+		Mood:       MoodHappy,
+		Intensity:  7,
+		Message:    "a good day",
+		Tags:       []string{"work", "health"},
+	}
+
+	tests := []struct {
+		name    string
+		change  func(e *Entry)
+		changed bool
+	}{
+		{"parent", func(e *Entry) { e.ParentHash = "abd" }, true},
+		{"created at", func(e *Entry) { e.CreatedAt = e.CreatedAt.Add(time.Second) }, true},
+		{"mood", func(e *Entry) { e.Mood = MoodCalm }, true},
+		{"intensity", func(e *Entry) { e.Intensity = 8 }, true},
+		{"message", func(e *Entry) { e.Message = "a good day!" }, true},
+		{"tag added", func(e *Entry) { e.Tags = append(e.Tags, "family") }, true},
+		{"tag order", func(e *Entry) { e.Tags = []string{"health", "work"} }, false},
+		{"updated at", func(e *Entry) { e.UpdatedAt = e.UpdatedAt.Add(time.Hour) }, false},
+		{"time zone", func(e *Entry) { e.CreatedAt = e.CreatedAt.In(time.FixedZone("UTC+2", 2*60*60)) }, false},
+		{"id", func(e *Entry) { e.ID = 42 }, false},
+	}
+
+	want := base.computeHash()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := base
+			e.Tags = append([]string{}, base.Tags...)
+			tt.change(&e)
+
+			if got := e.computeHash(); (got != want) != tt.changed {
+				t.Errorf("hash changed: %v, want %v", got != want, tt.changed)
+			}
+		})
+	}
+}
+
+func TestFsck(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper string
+		kinds  []string // the problems found with entry 2
+	}{
+		{"intact", "", nil},
+		{"message rewritten", `UPDATE entries SET message = 'rewritten' WHERE id = 2`, []string{FsckTampered}},
+		{"intensity rewritten", `UPDATE entries SET intensity = 1 WHERE id = 2`, []string{FsckTampered}},
+		// the parent is hashed too
+		{"parent rewritten", `UPDATE entries SET parent_hash = '' WHERE id = 2`, []string{FsckReordered, FsckTampered}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			openTestDB(t)
+
+			for _, message := range []string{"one", "two", "three"} {
+				if _, err := AddEntry(Entry{Mood: MoodNeutral, Intensity: 5, Message: message}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if tt.tamper != "" {
+				if _, err := db.Exec(tt.tamper); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			checked, problems, err := Fsck()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if checked != 3 {
+				t.Errorf("checked %d entries, want 3", checked)
+			}
+
+			var kinds []string
+			for _, p := range problems {
+				if p.Entry.ID != 2 {
+					t.Errorf("found a %s problem with entry %d", p.Kind, p.Entry.ID)
+				}
+				kinds = append(kinds, p.Kind)
+			}
+			if !slices.Equal(kinds, tt.kinds) {
+				t.Errorf("found %q with entry 2, want %q", kinds, tt.kinds)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gookit/color"
+)
+
+const (
+	HeatmapMood      = "mood"
+	HeatmapIntensity = "intensity"
+	HeatmapCount     = "count"
+	HeatmapScore     = "score"
+	// HeatmapValence is the name the score metric had before moods were
+	// scored, it still works as an alias.
+	HeatmapValence = "valence"
+)
+
+const heatmapCell = "■"
+
+var (
+	heatmapEmpty = color.C256(237)
+	// shades from low to high, like github's contribution graph
+	heatmapGreens = []color.Color256{color.C256(22), color.C256(28), color.C256(34), color.C256(40), color.C256(46)}
+	// from very negative through neutral to very positive
+	heatmapDiverging = []color.Color256{color.C256(160), color.C256(174), color.C256(250), color.C256(108), color.C256(34)}
+)
+
+type HeatmapDay struct {
+	Count         int
+	MeanIntensity float64
+	MeanScore     float64
+	DominantMood  Mood
+}
+
+// Heatmap holds one cell per local day between From and To (both inclusive).
+type Heatmap struct {
+	From      time.Time
+	To        time.Time
+	WeekStart time.Weekday
+	Metric    string
+	Days      map[string]HeatmapDay
+}
+
+// GetHeatmap aggregates entries per local day. the dominant mood of a day is
+// the most frequent one, ties go to the higher total intensity.
+func GetHeatmap(from, to time.Time, weekStart time.Weekday, metric string) (*Heatmap, error) {
+	switch metric {
+	case HeatmapMood, HeatmapIntensity, HeatmapCount, HeatmapScore:
+	case HeatmapValence:
+		metric = HeatmapScore
+	default:
+		return nil, fmt.Errorf("unknown metric %q, expected mood, intensity, count or score (valence)", metric)
+	}
+
+	h := &Heatmap{
+		From:      startOfDay(from),
+		To:        startOfDay(to),
+		WeekStart: weekStart,
+		Metric:    metric,
+		Days:      map[string]HeatmapDay{},
+	}
+
+	where, args := HistoryFilter{Since: h.From, Until: h.To.AddDate(0, 0, 1)}.where()
+	rows, err := db.Query(`
+		WITH per_mood AS (
+			SELECT local_date(created_at) AS day, mood, COUNT(*) AS n,
+				SUM(intensity) AS intensity, SUM(mood_score(mood, intensity)) AS score
+			FROM entries WHERE `+where+`
+			GROUP BY day, mood
+		), ranked AS (
+			SELECT day, mood,
+				ROW_NUMBER() OVER (PARTITION BY day ORDER BY n DESC, intensity DESC) AS rank,
+				SUM(n) OVER (PARTITION BY day) AS n,
+				SUM(intensity) OVER (PARTITION BY day) AS intensity,
+				SUM(score) OVER (PARTITION BY day) AS score
+			FROM per_mood
+		)
+		SELECT day, mood, n, intensity * 1.0 / n, score / n FROM ranked WHERE rank = 1`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var day string
+		var d HeatmapDay
+		if err := rows.Scan(&day, &d.DominantMood, &d.Count, &d.MeanIntensity, &d.MeanScore); err != nil {
+			return nil, err
+		}
+		h.Days[day] = d
+	}
+
+	return h, rows.Err()
+}
+
+// Render draws the grid with one column per week and one row per weekday,
+// dropping the oldest weeks if the terminal is too narrow.
+func (h *Heatmap) Render(width int) string {
+	const labelWidth = 4
+
+	gridStart := StartOfWeek(h.From, h.WeekStart)
+	weeks := int(h.To.Sub(gridStart).Hours()/24)/7 + 1
+
+	if maxWeeks := (width - labelWidth) / 2; weeks > maxWeeks && maxWeeks > 0 {
+		gridStart = gridStart.AddDate(0, 0, 7*(weeks-maxWeeks))
+		weeks = maxWeeks
+	}
+
+	maxCount := 0
+	for _, d := range h.Days {
+		maxCount = max(maxCount, d.Count)
+	}
+
+	var b strings.Builder
+	b.WriteString(h.monthLabels(gridStart, weeks, labelWidth))
+	b.WriteString("\n")
+
+	for row := 0; row < 7; row++ {
+		weekday := time.Weekday((int(h.WeekStart) + row) % 7)
+		label := ""
+		if row%2 == 1 {
+			label = weekday.String()[:3]
+		}
+		fmt.Fprintf(&b, "%-*s", labelWidth, label)
+
+		for col := 0; col < weeks; col++ {
+			day := gridStart.AddDate(0, 0, col*7+row)
+			if day.Before(h.From) || day.After(h.To) {
+				b.WriteString("  ")
+				continue
+			}
+
+			d, ok := h.Days[day.Format("2006-01-02")]
+			if !ok {
+				b.WriteString(heatmapEmpty.Sprint(heatmapCell) + " ")
+				continue
+			}
+
+			b.WriteString(h.cellColor(d, maxCount).Sprint(heatmapCell) + " ")
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(h.legend())
+
+	return b.String()
+}
+
+func (h *Heatmap) monthLabels(gridStart time.Time, weeks int, labelWidth int) string {
+	line := []rune(strings.Repeat(" ", labelWidth+weeks*2))
+	nextFree := 0
+
+	for col := 0; col < weeks; col++ {
+		weekStart := gridStart.AddDate(0, 0, col*7)
+		// label the column of the week in which a month starts
+		for i := 0; i < 7; i++ {
+			day := weekStart.AddDate(0, 0, i)
+			if day.Day() != 1 && !(col == 0 && i == 0) {
+				continue
+			}
+
+			pos := labelWidth + col*2
+			label := []rune(day.Format("Jan"))
+			if pos >= nextFree && pos+len(label) <= len(line) {
+				copy(line[pos:], label)
+				nextFree = pos + len(label) + 1
+			}
+			break
+		}
+	}
+
+	return strings.TrimRight(string(line), " ")
+}
+
+func (h *Heatmap) cellColor(d HeatmapDay, maxCount int) colorPrinter {
+	switch h.Metric {
+	case HeatmapIntensity:
+		return heatmapGreens[min(int(d.MeanIntensity/10*float64(len(heatmapGreens))), len(heatmapGreens)-1)]
+	case HeatmapCount:
+		level := (d.Count*len(heatmapGreens) - 1) / maxCount
+		return heatmapGreens[min(level, len(heatmapGreens)-1)]
+	case HeatmapScore:
+		// the score from -10 to 10 onto the diverging palette, -2..2 counts as
+		// neutral
+		switch v := d.MeanScore; {
+		case v <= -6:
+			return heatmapDiverging[0]
+		case v < -2:
+			return heatmapDiverging[1]
+		case v <= 2:
+			return heatmapDiverging[2]
+		case v < 6:
+			return heatmapDiverging[3]
+		default:
+			return heatmapDiverging[4]
+		}
+	}
+
+	entry := Entry{Mood: d.DominantMood}
+	return entry.getMoodColor()
+}
+
+func (h *Heatmap) legend() string {
+	var cells []string
+
+	switch h.Metric {
+	case HeatmapIntensity, HeatmapCount:
+		for _, c := range heatmapGreens {
+			cells = append(cells, c.Sprint(heatmapCell))
+		}
+		return fmt.Sprintf("%s less %s more", h.Metric, strings.Join(cells, " "))
+
+	case HeatmapScore:
+		for _, c := range heatmapDiverging {
+			cells = append(cells, c.Sprint(heatmapCell))
+		}
+		return fmt.Sprintf("score negative %s positive", strings.Join(cells, " "))
+	}
+
+	seen := map[Mood]bool{}
+	for _, d := range h.Days {
+		seen[d.DominantMood] = true
+	}
+
+	moods := make([]Mood, 0, len(seen))
+	for mood := range seen {
+		moods = append(moods, mood)
+	}
+	sort.Strings(moods)
+
+	for _, mood := range moods {
+		entry := Entry{Mood: mood}
+		cells = append(cells, entry.getMoodColor().Sprint(heatmapCell)+" "+mood)
+	}
+
+	return strings.Join(append(cells, heatmapEmpty.Sprint(heatmapCell)+" no entries"), "  ")
+}
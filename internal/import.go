@@ -0,0 +1,316 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ImportJSON   = "json"
+	ImportNDJSON = "ndjson"
+	ImportCSV    = "csv"
+)
+
+// ImportFormats lists the formats ReadImport understands, the same ones
+// Export writes except markdown.
+var ImportFormats = []string{ImportJSON, ImportNDJSON, ImportCSV}
+
+// ImportFields are the entry fields a csv column can be mapped to.
+var ImportFields = []string{"created_at", "updated_at", "tz", "mood", "intensity", "message", "tags"}
+
+// ImportRecord is an entry read from an import file, Source says where it
+// came from for error messages, e.g. "line 12".
+type ImportRecord struct {
+	Source string
+	Entry  Entry
+}
+
+// ImportResult tells what an import added and what it skipped.
+type ImportResult struct {
+	Added      []Entry
+	Duplicates []ImportRecord
+}
+
+// importJSON is the part of the json schema an import reads, the hashes,
+// ids and scores of the exporting journal are recomputed.
+type importJSON struct {
+	CreatedAt string   `json:"created_at"`
+	UpdatedAt string   `json:"updated_at"`
+	TimeZone  string   `json:"tz"`
+	Mood      Mood     `json:"mood"`
+	Intensity *int8    `json:"intensity"`
+	Message   string   `json:"message"`
+	Tags      []string `json:"tags"`
+}
+
+// DetectImportFormat guesses the format of an import file from its name and
+// falls back to looking at its first character.
+func DetectImportFormat(name string, head []byte) string {
+	switch {
+	case strings.HasSuffix(name, ".csv"):
+		return ImportCSV
+	case strings.HasSuffix(name, ".ndjson"), strings.HasSuffix(name, ".jsonl"):
+		return ImportNDJSON
+	}
+
+	switch trimmed := bytes.TrimSpace(head); {
+	case bytes.HasPrefix(trimmed, []byte("[")):
+		return ImportJSON
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		return ImportNDJSON
+	}
+	return ImportCSV
+}
+
+// ReadImport parses an import file. columns maps entry fields to csv column
+// names, fields that aren't mapped use the column of the same name.
+func ReadImport(r io.Reader, format string, columns map[string]string) ([]ImportRecord, error) {
+	switch format {
+	case ImportJSON:
+		return readJSONImport(r)
+	case ImportNDJSON:
+		return readNDJSONImport(r)
+	case ImportCSV:
+		return readCSVImport(r, columns)
+	}
+	return nil, fmt.Errorf("unknown import format %q, expected one of %s", format, strings.Join(ImportFormats, ", "))
+}
+
+func readJSONImport(r io.Reader) ([]ImportRecord, error) {
+	var items []importJSON
+	if err := json.NewDecoder(r).Decode(&items); err != nil {
+		return nil, fmt.Errorf("invalid json: %w", err)
+	}
+
+	records := make([]ImportRecord, len(items))
+	for i, item := range items {
+		source := fmt.Sprintf("entry %d", i+1)
+		entry, err := item.entry()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", source, err)
+		}
+		records[i] = ImportRecord{Source: source, Entry: entry}
+	}
+
+	return records, nil
+}
+
+func readNDJSONImport(r io.Reader) ([]ImportRecord, error) {
+	var records []ImportRecord
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		source := fmt.Sprintf("line %d", line)
+		var item importJSON
+		if err := json.Unmarshal(scanner.Bytes(), &item); err != nil {
+			return nil, fmt.Errorf("%s: invalid json: %w", source, err)
+		}
+
+		entry, err := item.entry()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", source, err)
+		}
+		records = append(records, ImportRecord{Source: source, Entry: entry})
+	}
+
+	return records, scanner.Err()
+}
+
+func (item importJSON) entry() (Entry, error) {
+	if item.Intensity == nil {
+		return Entry{}, fmt.Errorf("intensity is missing")
+	}
+
+	return importEntry(map[string]string{
+		"created_at": item.CreatedAt,
+		"updated_at": item.UpdatedAt,
+		"tz":         item.TimeZone,
+		"mood":       item.Mood,
+		"intensity":  strconv.Itoa(int(*item.Intensity)),
+		"message":    item.Message,
+	}, item.Tags)
+}
+
+func readCSVImport(r io.Reader, columns map[string]string) ([]ImportRecord, error) {
+	for field := range columns {
+		if !slices.Contains(ImportFields, field) {
+			return nil, fmt.Errorf("unknown field %q, expected one of %s", field, strings.Join(ImportFields, ", "))
+		}
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("invalid csv: %w", err)
+	}
+
+	index := map[string]int{}
+	for _, field := range ImportFields {
+		column := field
+		if mapped, ok := columns[field]; ok {
+			column = mapped
+		}
+
+		i := slices.IndexFunc(header, func(name string) bool {
+			return strings.EqualFold(strings.TrimSpace(name), column)
+		})
+		if i < 0 && slices.Contains([]string{"created_at", "mood", "intensity"}, field) {
+			return nil, fmt.Errorf("no %q column for %s, map it with --column %s=<name>", column, field, field)
+		}
+		index[field] = i
+	}
+
+	var records []ImportRecord
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		source := fmt.Sprintf("line %d", line)
+
+		values := map[string]string{}
+		for field, i := range index {
+			if i >= 0 && i < len(row) {
+				values[field] = row[i]
+			}
+		}
+
+		entry, err := importEntry(values, strings.Split(values["tags"], ","))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", source, err)
+		}
+		records = append(records, ImportRecord{Source: source, Entry: entry})
+	}
+
+	return records, nil
+}
+
+// importEntry builds an entry from the text of its fields. the mood and the
+// tags are checked later, when the entry is validated. the message is kept
+// exactly as given, like add stores it, so a re-imported export finds its
+// duplicates.
+func importEntry(values map[string]string, tags []string) (Entry, error) {
+	entry := Entry{
+		Mood:     strings.TrimSpace(values["mood"]),
+		Message:  values["message"],
+		Tags:     tags,
+		TimeZone: strings.TrimSpace(values["tz"]),
+	}
+
+	intensity, err := strconv.ParseInt(strings.TrimSpace(values["intensity"]), 10, 8)
+	if err != nil {
+		return Entry{}, fmt.Errorf("invalid intensity %q", values["intensity"])
+	}
+	entry.Intensity = int8(intensity)
+
+	if entry.CreatedAt, err = parseImportTime(values["created_at"]); err != nil {
+		return Entry{}, fmt.Errorf("invalid created_at: %w", err)
+	}
+
+	// entries that were never edited keep looking that way
+	entry.UpdatedAt = entry.CreatedAt
+	if updated := strings.TrimSpace(values["updated_at"]); updated != "" {
+		if entry.UpdatedAt, err = parseImportTime(updated); err != nil {
+			return Entry{}, fmt.Errorf("invalid updated_at: %w", err)
+		}
+	}
+
+	return entry, nil
+}
+
+// parseImportTime reads an absolute timestamp, timestamps without an offset
+// are taken in the display location.
+func parseImportTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, fmt.Errorf("timestamp is missing")
+	}
+
+	for _, layout := range append(absoluteLayouts, dateLayouts...) {
+		if t, err := time.ParseInLocation(layout, s, displayLocation); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognized timestamp %q", s)
+}
+
+// ImportEntries adds the records oldest first in a single transaction, so
+// either all of them are imported or none. records with the same time, mood
+// and message as an existing entry, or an earlier record, are skipped. with
+// dryRun the transaction is rolled back, the result shows what would have
+// happened.
+func ImportEntries(records []ImportRecord, dryRun bool) (ImportResult, error) {
+	var result ImportResult
+
+	sorted := slices.Clone(records)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Entry.CreatedAt.Before(sorted[j].Entry.CreatedAt)
+	})
+
+	tx, err := db.Begin()
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback()
+
+	for _, record := range sorted {
+		entry := record.Entry
+		if err := entry.validate(); err != nil {
+			return ImportResult{}, fmt.Errorf("%s: %w", record.Source, err)
+		}
+
+		// csv readers turn the \r\n in a quoted field into \n, line endings
+		// don't make an entry new
+		var duplicate bool
+		err := tx.QueryRow(`
+			SELECT EXISTS (SELECT 1 FROM entries
+				WHERE created_at = ? AND mood = ? AND replace(message, char(13, 10), char(10)) = ?)`,
+			entry.CreatedAt.UTC().Truncate(time.Second).Format(sqliteTimeLayout), entry.Mood,
+			strings.ReplaceAll(entry.Message, "\r\n", "\n")).Scan(&duplicate)
+		if err != nil {
+			return ImportResult{}, err
+		}
+		if duplicate {
+			result.Duplicates = append(result.Duplicates, ImportRecord{Source: record.Source, Entry: entry})
+			continue
+		}
+
+		added, err := insertEntry(tx, entry)
+		if err != nil {
+			return ImportResult{}, fmt.Errorf("%s: %w", record.Source, err)
+		}
+		result.Added = append(result.Added, added)
+	}
+
+	if dryRun {
+		return result, nil
+	}
+
+	return result, tx.Commit()
+}
@@ -0,0 +1,294 @@
+package internal
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseImportTime(t *testing.T) {
+	inUTC(t)
+
+	tests := []struct {
+		input string
+		want  time.Time
+	}{
+		{"2026-10-16T09:30:00+02:00", time.Date(2026, 10, 16, 7, 30, 0, 0, time.UTC)},
+		{" 2026-10-16T09:30:00Z ", time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)},
+		{"2026-10-16T09:30", time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)},
+		{"2026-10-16 09:30:15", time.Date(2026, 10, 16, 9, 30, 15, 0, time.UTC)},
+		{"2026/10/16 09:30", time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)},
+		{"2026-10-16", time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseImportTime(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseImportTime(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+
+	// relative times depend on when the import runs
+	for _, input := range []string{"", "yesterday", "-3h", "16.10.2026"} {
+		if got, err := parseImportTime(input); err == nil {
+			t.Errorf("parseImportTime(%q) = %v, want an error", input, got)
+		}
+	}
+}
+
+func TestDetectImportFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		head string
+		want string
+	}{
+		{"moods.csv", `[{"mood": "happy"}]`, ImportCSV},
+		{"moods.ndjson", "[", ImportNDJSON},
+		{"moods.jsonl", "", ImportNDJSON},
+		{"moods.json", "\n  [\n", ImportJSON},
+		{"-", `{"mood": "happy"}`, ImportNDJSON},
+		{"moods.txt", "created_at,mood,intensity", ImportCSV},
+		{"-", "", ImportCSV},
+	}
+
+	for _, tt := range tests {
+		if got := DetectImportFormat(tt.name, []byte(tt.head)); got != tt.want {
+			t.Errorf("DetectImportFormat(%q, %q) = %s, want %s", tt.name, tt.head, got, tt.want)
+		}
+	}
+}
+
+func TestReadImport(t *testing.T) {
+	inUTC(t)
+
+	created := time.Date(2026, 10, 16, 7, 30, 0, 0, time.UTC)
+	want := Entry{
+		CreatedAt: created,
+		UpdatedAt: created,
+		TimeZone:  "Europe/Berlin",
+		Mood:      MoodHappy,
+		Intensity: 8,
+		Message:   "got a promotion",
+	}
+
+	tests := []struct {
+		name    string
+		format  string
+		columns map[string]string
+		input   string
+		tags    []string
+	}{
+		{
+			name:   "json",
+			format: ImportJSON,
+			input:  `[{"created_at": "2026-10-16T09:30:00+02:00", "tz": "Europe/Berlin", "mood": "happy", "intensity": 8, "message": "got a promotion", "tags": ["work"], "hash": "ignored"}]`,
+			tags:   []string{"work"},
+		},
+		{
+			name:   "ndjson",
+			format: ImportNDJSON,
+			input:  "\n" + `{"created_at": "2026-10-16T07:30:00Z", "tz": "Europe/Berlin", "mood": "happy", "intensity": 8, "message": "got a promotion"}` + "\n\n",
+		},
+		{
+			name:   "csv",
+			format: ImportCSV,
+			input:  "Mood,Intensity,Created_At,tz,message,tags,score\nhappy,8,2026-10-16 07:30,Europe/Berlin,got a promotion,\"work, career\",0.8\n",
+			tags:   []string{"work", " career"},
+		},
+		{
+			name:    "csv with mapped columns",
+			format:  ImportCSV,
+			columns: map[string]string{"created_at": "when", "message": "note", "mood": "feeling"},
+			input:   "when,feeling,intensity,tz,note\n2026-10-16 07:30,happy,8,Europe/Berlin,got a promotion\n",
+			tags:    []string{""},
+		},
+		{
+			name:   "csv with a short row",
+			format: ImportCSV,
+			input:  "created_at,mood,intensity,tz,message,tags\n2026-10-16 07:30,happy,8,Europe/Berlin,got a promotion\n",
+			tags:   []string{""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := ReadImport(strings.NewReader(tt.input), tt.format, tt.columns)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(records) != 1 {
+				t.Fatalf("read %d records, want 1", len(records))
+			}
+
+			got := records[0].Entry
+			if !slices.Equal(got.Tags, tt.tags) {
+				t.Errorf("tags are %q, want %q", got.Tags, tt.tags)
+			}
+			got.Tags = nil
+			if !got.CreatedAt.Equal(want.CreatedAt) || !got.UpdatedAt.Equal(want.UpdatedAt) {
+				t.Errorf("read an entry created at %v and updated at %v, want %v", got.CreatedAt, got.UpdatedAt, want.CreatedAt)
+			}
+			got.CreatedAt, got.UpdatedAt = want.CreatedAt, want.UpdatedAt
+			if got.TimeZone != want.TimeZone || got.Mood != want.Mood || got.Intensity != want.Intensity || got.Message != want.Message {
+				t.Errorf("read %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestReadImportErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		columns map[string]string
+		input   string
+		want    string
+	}{
+		{"unknown format", "yaml", nil, "", "unknown import format"},
+		{"invalid json", ImportJSON, nil, `{"mood": "happy"}`, "invalid json"},
+		{"missing intensity", ImportJSON, nil, `[{"created_at": "2026-10-16", "mood": "happy"}]`, "entry 1: intensity is missing"},
+		{"invalid ndjson line", ImportNDJSON, nil, "{\"created_at\": \"2026-10-16\", \"mood\": \"sad\", \"intensity\": 2}\nnot json\n", "line 2: invalid json"},
+		{"missing created_at", ImportNDJSON, nil, `{"mood": "sad", "intensity": 2}`, "invalid created_at"},
+		{"invalid updated_at", ImportNDJSON, nil, `{"created_at": "2026-10-16", "updated_at": "later", "mood": "sad", "intensity": 2}`, "invalid updated_at"},
+		{"unknown field", ImportCSV, map[string]string{"feeling": "mood"}, "mood\n", `unknown field "feeling"`},
+		{"missing column", ImportCSV, nil, "created_at,mood\n2026-10-16,sad\n", `no "intensity" column for intensity`},
+		{"missing mapped column", ImportCSV, map[string]string{"mood": "feeling"}, "created_at,mood,intensity\n", `no "feeling" column for mood`},
+		{"invalid intensity", ImportCSV, nil, "created_at,mood,intensity\n2026-10-16,sad,2\n2026-10-17,sad,low\n", `line 3: invalid intensity "low"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadImport(strings.NewReader(tt.input), tt.format, tt.columns)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ReadImport = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportEntries(t *testing.T) {
+	openTestDB(t)
+
+	day := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	if _, err := AddEntry(Entry{CreatedAt: day, Mood: MoodCalm, Intensity: 4, Message: "already there"}); err != nil {
+		t.Fatal(err)
+	}
+
+	record := func(source string, hours int, mood Mood, message string) ImportRecord {
+		created := day.Add(time.Duration(hours) * time.Hour)
+		return ImportRecord{Source: source, Entry: Entry{CreatedAt: created, UpdatedAt: created, Mood: mood, Intensity: 5, Message: message}}
+	}
+	records := []ImportRecord{
+		record("line 2", 2, "Hap", "later"),
+		record("line 3", 0, MoodCalm, "already there"),
+		record("line 4", -2, MoodSad, "earlier"),
+		record("line 5", 2, MoodHappy, "later"),
+	}
+
+	tests := []struct {
+		name   string
+		dryRun bool
+		added  []string // the messages of the added entries
+		dupes  []string // the sources of the skipped records
+		count  int      // entries afterwards
+	}{
+		{"dry run", true, []string{"earlier", "later"}, []string{"line 3", "line 5"}, 1},
+		{"import", false, []string{"earlier", "later"}, []string{"line 3", "line 5"}, 3},
+		{"again", false, nil, []string{"line 4", "line 3", "line 2", "line 5"}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ImportEntries(records, tt.dryRun)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var added, dupes []string
+			for _, e := range result.Added {
+				added = append(added, e.Message)
+			}
+			for _, r := range result.Duplicates {
+				dupes = append(dupes, r.Source)
+			}
+
+			if !slices.Equal(added, tt.added) || !slices.Equal(dupes, tt.dupes) {
+				t.Errorf("added %q and skipped %q, want %q and %q", added, dupes, tt.added, tt.dupes)
+			}
+
+			count, err := CountEntries(HistoryFilter{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != tt.count {
+				t.Errorf("the journal has %d entries, want %d", count, tt.count)
+			}
+		})
+	}
+
+	checked, problems, err := Fsck()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checked != 3 || len(problems) > 0 {
+		t.Errorf("fsck checked %d entries and found %v, want 3 and none", checked, problems)
+	}
+
+	if _, err := ImportEntries([]ImportRecord{record("line 9", 5, "furious", "")}, false); err == nil || !strings.HasPrefix(err.Error(), "line 9: ") {
+		t.Errorf("importing an invalid mood = %v, want an error for line 9", err)
+	}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	openTestDB(t)
+
+	created := time.Now().Add(-48 * time.Hour).Truncate(time.Second)
+	for i, e := range []Entry{
+		{Mood: MoodHappy, Intensity: 8, Message: "got a promotion", Tags: []string{"work", "achievement"}},
+		{Mood: MoodCalm, Intensity: 4, Message: "  indented, with a trailing newline\n"},
+		{Mood: MoodSad, Intensity: 2, Message: "line one\n\nline \"two\", with commas"},
+		{Mood: MoodTired, Intensity: 6, Message: " ", Tags: []string{"late night"}},
+		{Mood: MoodNeutral, Intensity: 5, TimeZone: "Europe/Berlin", Message: "windows\r\nline"},
+	} {
+		e.CreatedAt = created.Add(time.Duration(i) * time.Hour)
+		if _, err := AddEntry(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, format := range []string{ExportJSON, ExportNDJSON, ExportCSV} {
+		t.Run(format, func(t *testing.T) {
+			var b strings.Builder
+			count, err := Export(&b, format, HistoryFilter{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			records, err := ReadImport(strings.NewReader(b.String()), format, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(records) != count {
+				t.Fatalf("read %d records from an export of %d entries", len(records), count)
+			}
+
+			result, err := ImportEntries(records, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result.Added) > 0 || len(result.Duplicates) != count {
+				var added []string
+				for _, e := range result.Added {
+					added = append(added, e.Message)
+				}
+				t.Errorf("re-importing the export would add %q, want only %d duplicates", added, count)
+			}
+		})
+	}
+}
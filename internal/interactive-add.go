@@ -0,0 +1,348 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gookit/color"
+)
+
+// ErrAddAborted is returned when the entry form is closed without saving.
+var ErrAddAborted = errors.New("aborted")
+
+type addField int
+
+const (
+	addFieldMood addField = iota
+	addFieldIntensity
+	addFieldMessage
+	addFieldTags
+	addFieldCount
+)
+
+var (
+	labelStyle       = lipgloss.NewStyle().Width(11).Foreground(lipgloss.Color("241"))
+	activeLabelStyle = labelStyle.Foreground(lipgloss.Color("205")).Bold(true)
+	errorStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	formHelpStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+)
+
+type InteractiveAddModel struct {
+	entry     Entry // the entry being filled in, CreatedAt may be preset
+	moods     []MoodDef
+	moodIndex int
+	message   textarea.Model
+	tags      textinput.Model
+	knownTags []string
+	focus     addField
+	width     int
+	err       error
+	added     *Entry
+}
+
+// NewInteractiveAddModel builds the form for a new entry. the entry may carry
+// a creation time, everything else is asked for.
+func NewInteractiveAddModel(entry Entry, knownTags []string) InteractiveAddModel {
+	message := textarea.New()
+	message.Placeholder = "describe your mood (optional)"
+	message.ShowLineNumbers = false
+	message.SetWidth(60)
+	message.SetHeight(4)
+
+	tags := textinput.New()
+	tags.Placeholder = "comma separated, tab completes"
+	tags.Prompt = ""
+	tags.ShowSuggestions = true
+	tags.Width = 58
+
+	m := InteractiveAddModel{
+		entry:     entry,
+		moods:     Moods(),
+		message:   message,
+		tags:      tags,
+		knownTags: knownTags,
+		width:     80,
+	}
+
+	m.entry.Intensity = 5
+	for i, def := range m.moods {
+		if def.Name == MoodNeutral {
+			m.moodIndex = i
+		}
+	}
+
+	m.updateTagSuggestions()
+	return m
+}
+
+func (m InteractiveAddModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m InteractiveAddModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			return m, tea.Quit
+
+		case "ctrl+s":
+			return m.submit()
+
+		case "shift+tab":
+			return m, m.setFocus((m.focus + addFieldCount - 1) % addFieldCount)
+
+		case "tab":
+			// tab completes a tag before it moves on
+			if m.focus != addFieldTags || !m.hasTagSuggestion() {
+				return m, m.setFocus((m.focus + 1) % addFieldCount)
+			}
+
+		case "enter":
+			switch m.focus {
+			case addFieldTags:
+				return m.submit()
+			case addFieldMood, addFieldIntensity:
+				return m, m.setFocus(m.focus + 1)
+			}
+		}
+
+		return m.updateField(msg)
+	}
+
+	return m, nil
+}
+
+func (m InteractiveAddModel) updateField(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	key := msg.String()
+
+	switch m.focus {
+	case addFieldMood:
+		switch key {
+		case "left", "up":
+			m.moodIndex = (m.moodIndex + len(m.moods) - 1) % len(m.moods)
+		case "right", "down":
+			m.moodIndex = (m.moodIndex + 1) % len(m.moods)
+		default:
+			// typing a letter jumps to the next mood starting with it
+			if len(msg.Runes) == 1 {
+				for i := 1; i <= len(m.moods); i++ {
+					next := (m.moodIndex + i) % len(m.moods)
+					if strings.HasPrefix(m.moods[next].Name, string(msg.Runes)) {
+						m.moodIndex = next
+						break
+					}
+				}
+			}
+		}
+
+	case addFieldIntensity:
+		switch key {
+		case "left", "h", "down", "j", "-":
+			m.entry.Intensity = max(m.entry.Intensity-1, MinIntensity)
+		case "right", "l", "up", "k", "+":
+			m.entry.Intensity = min(m.entry.Intensity+1, MaxIntensity)
+		case "home":
+			m.entry.Intensity = MinIntensity
+		case "end":
+			m.entry.Intensity = MaxIntensity
+		default:
+			if len(key) == 1 && key[0] >= '0' && key[0] <= '9' {
+				m.entry.Intensity = int8(key[0] - '0')
+			}
+		}
+
+	case addFieldMessage:
+		m.message, cmd = m.message.Update(msg)
+
+	case addFieldTags:
+		m.tags, cmd = m.tags.Update(msg)
+		m.updateTagSuggestions()
+	}
+
+	m.err = nil
+	return m, cmd
+}
+
+func (m *InteractiveAddModel) setFocus(field addField) tea.Cmd {
+	m.focus = field
+	m.message.Blur()
+	m.tags.Blur()
+
+	switch field {
+	case addFieldMessage:
+		return m.message.Focus()
+	case addFieldTags:
+		return m.tags.Focus()
+	}
+	return nil
+}
+
+// submit adds the entry, the form stays open with the error if that fails.
+func (m InteractiveAddModel) submit() (tea.Model, tea.Cmd) {
+	entry := m.entry
+	entry.Mood = m.moods[m.moodIndex].Name
+	entry.Message = strings.TrimSpace(m.message.Value())
+	entry.Tags = strings.Split(m.tags.Value(), ",")
+
+	added, err := AddEntry(entry)
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+
+	m.added = &added
+	return m, tea.Quit
+}
+
+// updateTagSuggestions offers the known tags for the tag being typed, the
+// ones already given aren't offered again.
+func (m *InteractiveAddModel) updateTagSuggestions() {
+	value := m.tags.Value()
+	cut := strings.LastIndex(value, ",") + 1
+	for cut < len(value) && value[cut] == ' ' {
+		cut++
+	}
+	prefix := value[:cut]
+
+	given := map[string]bool{}
+	for _, tag := range strings.Split(prefix, ",") {
+		given[strings.TrimSpace(tag)] = true
+	}
+
+	suggestions := []string{}
+	for _, tag := range m.knownTags {
+		if !given[tag] {
+			suggestions = append(suggestions, prefix+tag)
+		}
+	}
+
+	m.tags.SetSuggestions(suggestions)
+}
+
+func (m InteractiveAddModel) hasTagSuggestion() bool {
+	for _, suggestion := range m.tags.MatchedSuggestions() {
+		if suggestion != m.tags.Value() {
+			return true
+		}
+	}
+	return false
+}
+
+func (m InteractiveAddModel) View() string {
+	if m.added != nil {
+		return ""
+	}
+
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render("🎭 new mood entry"))
+	if !m.entry.CreatedAt.IsZero() {
+		s.WriteString(formHelpStyle.Render("at " + localTime(m.entry.CreatedAt).Format("2006/01/02 15:04")))
+	}
+	s.WriteString("\n\n")
+
+	s.WriteString(m.label(addFieldMood, "mood") + m.moodPicker() + "\n\n")
+	s.WriteString(m.label(addFieldIntensity, "intensity") + m.slider() + "\n\n")
+	s.WriteString(m.label(addFieldMessage, "message") + "\n" + m.message.View() + "\n\n")
+	s.WriteString(m.label(addFieldTags, "tags") + m.tags.View() + "\n\n")
+
+	if m.err != nil {
+		s.WriteString(errorStyle.Render("error: "+m.err.Error()) + "\n\n")
+	}
+
+	help := "tab/shift+tab: next/previous field | ←/→: change | ctrl+s: save | esc: cancel"
+	if m.focus == addFieldTags {
+		help = "tab: complete tag | ↑/↓: other suggestions | enter, ctrl+s: save | esc: cancel"
+	}
+	s.WriteString(formHelpStyle.Render(help) + "\n")
+
+	return s.String()
+}
+
+func (m InteractiveAddModel) label(field addField, text string) string {
+	if m.focus == field {
+		return activeLabelStyle.Render(text)
+	}
+	return labelStyle.Render(text)
+}
+
+// moodPicker lists the moods in their colours and wraps them to the
+// terminal, the selected one is shown reversed.
+func (m InteractiveAddModel) moodPicker() string {
+	const indent = 11
+
+	var lines []string
+	line, lineWidth := "", 0
+	for i, def := range m.moods {
+		label := " " + def.Label() + " "
+		style := def.style()
+		if i == m.moodIndex {
+			style = style.with(color.OpReverse)
+		}
+
+		width := lipgloss.Width(label) + 1
+		if lineWidth > 0 && indent+lineWidth+width > m.width {
+			lines = append(lines, line)
+			line, lineWidth = "", 0
+		}
+		line += style.Sprint(label) + " "
+		lineWidth += width
+	}
+	lines = append(lines, line)
+
+	return strings.Join(lines, "\n"+strings.Repeat(" ", indent))
+}
+
+func (m InteractiveAddModel) slider() string {
+	entry := Entry{Mood: m.moods[m.moodIndex].Name, Intensity: m.entry.Intensity}
+	style := entry.getIntensityStyle(entry.getMoodColor())
+
+	var track strings.Builder
+	for i := MinIntensity; i <= MaxIntensity; i++ {
+		switch {
+		case i == int(m.entry.Intensity):
+			track.WriteString("●")
+		case i < int(m.entry.Intensity):
+			track.WriteString("━")
+		default:
+			track.WriteString(color.Gray.Sprint("─"))
+		}
+	}
+
+	return fmt.Sprintf("%s %s", style.Sprint(track.String()), style.Sprintf("%02d/10", m.entry.Intensity))
+}
+
+// StartInteractiveAdd asks for a new entry in a form and adds it. it returns
+// ErrAddAborted if the form is closed without saving.
+func StartInteractiveAdd(entry Entry) (Entry, error) {
+	counts, err := TagCounts()
+	if err != nil {
+		return Entry{}, err
+	}
+
+	knownTags := make([]string, len(counts))
+	for i, tc := range counts {
+		knownTags[i] = tc.Tag
+	}
+
+	final, err := tea.NewProgram(NewInteractiveAddModel(entry, knownTags)).Run()
+	if err != nil {
+		return Entry{}, err
+	}
+
+	if added := final.(InteractiveAddModel).added; added != nil {
+		return *added, nil
+	}
+	return Entry{}, ErrAddAborted
+}
@@ -34,6 +34,7 @@ var (
 
 type InteractiveLogModel struct {
 	table        table.Model
+	baseFilter   HistoryFilter
 	entries      []Entry
 	showHelp     bool
 	searchMode   bool
@@ -47,8 +48,9 @@ type InteractiveLogModel struct {
 	totalPages   int
 }
 
-func NewInteractiveLogModel(pageSize int) InteractiveLogModel {
+func NewInteractiveLogModel(pageSize int, baseFilter HistoryFilter) InteractiveLogModel {
 	columns := []table.Column{
+		{Title: "hash", Width: 7},
 		{Title: "date", Width: 16},
 		{Title: "mood", Width: 12},
 		{Title: "intensity", Width: 9},
@@ -76,6 +78,7 @@ func NewInteractiveLogModel(pageSize int) InteractiveLogModel {
 
 	return InteractiveLogModel{
 		table:        t,
+		baseFilter:   baseFilter,
 		entries:      []Entry{},
 		showHelp:     false,
 		searchMode:   false,
@@ -97,7 +100,7 @@ type entriesLoadedMsg struct {
 }
 
 func (m InteractiveLogModel) Init() tea.Cmd {
-	return loadEntries(m.pageSize, m.currentPage, m.filterMode, m.searchQuery)
+	return loadEntries(m.baseFilter, m.pageSize, m.currentPage, m.filterMode, m.searchQuery)
 }
 
 func (m InteractiveLogModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -110,7 +113,7 @@ func (m InteractiveLogModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tickMsg:
-		return m, loadEntries(m.pageSize, m.currentPage, m.filterMode, m.searchQuery)
+		return m, loadEntries(m.baseFilter, m.pageSize, m.currentPage, m.filterMode, m.searchQuery)
 
 	case entriesLoadedMsg:
 		m.entries = msg.entries
@@ -168,10 +171,10 @@ func (m InteractiveLogModel) View() string {
 	return s.String()
 }
 
-func loadEntries(pageSize int, page int, filter string, search string) tea.Cmd {
+func loadEntries(base HistoryFilter, pageSize int, page int, filter string, search string) tea.Cmd {
 	return func() tea.Msg {
 		offset := page * pageSize
-		entries, totalCount, err := getFilteredHistory(pageSize, offset, filter, search)
+		entries, totalCount, err := getFilteredHistory(base, pageSize, offset, filter, search)
 		if err != nil {
 			return entriesLoadedMsg{entries: []Entry{}, totalEntries: 0, currentPage: page}
 		}
@@ -195,7 +198,8 @@ func (m *InteractiveLogModel) updateTableRows() {
 		}
 
 		rows = append(rows, table.Row{
-			entry.CreatedAt.Format("2006/01/02 15:04"),
+			entry.ShortHash(),
+			localTime(entry.CreatedAt).Format("2006/01/02 15:04"),
 			entry.Mood,
 			intensityStr,
 			message,
@@ -220,22 +224,27 @@ func (m InteractiveLogModel) handleNormalInput(msg tea.KeyMsg) (tea.Model, tea.C
 		m.searchQuery = ""
 
 	case "r":
-		return m, loadEntries(m.pageSize, m.currentPage, m.filterMode, m.searchQuery)
+		return m, loadEntries(m.baseFilter, m.pageSize, m.currentPage, m.filterMode, m.searchQuery)
 
 	case "left", "h":
 		if m.currentPage > 0 {
 			m.currentPage--
-			return m, loadEntries(m.pageSize, m.currentPage, m.filterMode, m.searchQuery)
+			return m, loadEntries(m.baseFilter, m.pageSize, m.currentPage, m.filterMode, m.searchQuery)
 		}
 
 	case "right", "l":
 		if m.currentPage < m.totalPages-1 {
 			m.currentPage++
-			return m, loadEntries(m.pageSize, m.currentPage, m.filterMode, m.searchQuery)
+			return m, loadEntries(m.baseFilter, m.pageSize, m.currentPage, m.filterMode, m.searchQuery)
 		}
 
 	case "f":
-		filters := []string{"all", "happy", "sad", "angry", "anxious", "excited", "calm", "stressed", "tired", "neutral"}
+		// with --mood only those moods are cycled through
+		moods := MoodNames()
+		if len(m.baseFilter.Moods) > 0 {
+			moods = m.baseFilter.Moods
+		}
+		filters := append([]string{"all"}, moods...)
 		for i, filter := range filters {
 			if filter == m.filterMode {
 				m.filterMode = filters[(i+1)%len(filters)]
@@ -243,7 +252,7 @@ func (m InteractiveLogModel) handleNormalInput(msg tea.KeyMsg) (tea.Model, tea.C
 			}
 		}
 		m.currentPage = 0
-		return m, loadEntries(m.pageSize, m.currentPage, m.filterMode, m.searchQuery)
+		return m, loadEntries(m.baseFilter, m.pageSize, m.currentPage, m.filterMode, m.searchQuery)
 	}
 
 	m.table, cmd = m.table.Update(msg)
@@ -255,13 +264,13 @@ func (m InteractiveLogModel) handleSearchInput(msg tea.KeyMsg) (tea.Model, tea.C
 	case "enter":
 		m.searchMode = false
 		m.currentPage = 0
-		return m, loadEntries(m.pageSize, m.currentPage, m.filterMode, m.searchQuery)
+		return m, loadEntries(m.baseFilter, m.pageSize, m.currentPage, m.filterMode, m.searchQuery)
 
 	case "esc":
 		m.searchMode = false
 		m.searchQuery = ""
 		m.currentPage = 0
-		return m, loadEntries(m.pageSize, m.currentPage, m.filterMode, m.searchQuery)
+		return m, loadEntries(m.baseFilter, m.pageSize, m.currentPage, m.filterMode, m.searchQuery)
 
 	case "backspace":
 		if len(m.searchQuery) > 0 {
@@ -294,7 +303,8 @@ actions:
   q, ctrl+c      quit
 
 search mode:
-  type to search in messages and tags
+  type words to search in messages, each word also
+  matches as a prefix, or a whole tag
   enter          apply search
   esc            cancel search
 
@@ -311,9 +321,9 @@ press ? again to return to the table view.`
 	return helpStyle.Render(help)
 }
 
-func StartInteractiveLog(pageSize int) error {
+func StartInteractiveLog(pageSize int, baseFilter HistoryFilter) error {
 	p := tea.NewProgram(
-		NewInteractiveLogModel(pageSize),
+		NewInteractiveLogModel(pageSize, baseFilter),
 		tea.WithAltScreen(),
 	)
 
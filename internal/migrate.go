@@ -0,0 +1,238 @@
+package internal
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// migrations are named NNNN_description.sql and applied in ascending order.
+// a migration must never be edited once released, add a new one instead.
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// migrationHooks are data changes that can't be expressed in sql alone. they
+// run after the sql of all pending migrations, in the same transaction,
+// because they use the queries of this binary, which expect the latest schema.
+var migrationHooks = map[int]func(tx *sql.Tx) error{
+	2: func(tx *sql.Tx) error { return rehashFrom(tx, 0) },
+	// moving tags into their own table drops duplicate and blank tags
+	6: func(tx *sql.Tx) error { return rehashFrom(tx, 0) },
+}
+
+type MigrationState struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+type DatabaseTooNewError struct {
+	Version   int
+	Supported int
+}
+
+func (e *DatabaseTooNewError) Error() string {
+	return fmt.Sprintf("database schema version %d is newer than this moodgit binary supports (%d).\nplease upgrade moodgit", e.Version, e.Supported)
+}
+
+func loadMigrations() ([]Migration, error) {
+	files, err := fs.Glob(migrationsFS, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []Migration
+	for _, file := range files {
+		base := strings.TrimSuffix(path.Base(file), ".sql")
+		versionStr, name, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("invalid migration file name %q", file)
+		}
+
+		version, err := strconv.Atoi(versionStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration file name %q: %w", file, err)
+		}
+
+		sqlBytes, err := migrationsFS.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		migrations = append(migrations, Migration{Version: version, Name: name, SQL: string(sqlBytes)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	for i := range migrations {
+		if migrations[i].Version != i+1 {
+			return nil, fmt.Errorf("migration %04d is missing", i+1)
+		}
+	}
+
+	return migrations, nil
+}
+
+func ensureVersionTable() error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_version (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`)
+	return err
+}
+
+func appliedMigrations() (map[int]time.Time, error) {
+	rows, err := db.Query(`SELECT version, applied_at FROM schema_version`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int]time.Time{}
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+
+	return applied, rows.Err()
+}
+
+// checkVersion refuses to touch databases written by a newer binary.
+func checkVersion(migrations []Migration) error {
+	var current int
+	if err := db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&current); err != nil {
+		return err
+	}
+
+	if current > len(migrations) {
+		return &DatabaseTooNewError{Version: current, Supported: len(migrations)}
+	}
+
+	return nil
+}
+
+// MigrationStatus lists every migration and whether it has been applied.
+func MigrationStatus() ([]MigrationState, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	// a status query never writes, a database without the version table
+	// simply has every migration pending
+	var exists bool
+	err = db.QueryRow(`SELECT EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'schema_version')`).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := map[int]time.Time{}
+	if exists {
+		if err := checkVersion(migrations); err != nil {
+			return nil, err
+		}
+
+		if applied, err = appliedMigrations(); err != nil {
+			return nil, err
+		}
+	}
+
+	states := make([]MigrationState, 0, len(migrations))
+	for _, m := range migrations {
+		appliedAt, ok := applied[m.Version]
+		states = append(states, MigrationState{Migration: m, Applied: ok, AppliedAt: appliedAt})
+	}
+
+	return states, nil
+}
+
+// Migrate applies all pending migrations and their hooks in a single
+// transaction and returns the ones that were applied. if any of them fails
+// the database is left as it was.
+func Migrate() ([]Migration, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ensureVersionTable(); err != nil {
+		return nil, err
+	}
+
+	if err := checkVersion(migrations); err != nil {
+		return nil, err
+	}
+
+	applied, err := appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; !ok {
+			pending = append(pending, m)
+		}
+	}
+
+	if len(pending) == 0 {
+		return nil, nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for _, m := range pending {
+		if err := applyMigration(tx, m); err != nil {
+			return nil, fmt.Errorf("migration %04d_%s failed: %w", m.Version, m.Name, err)
+		}
+	}
+
+	for _, m := range pending {
+		hook, ok := migrationHooks[m.Version]
+		if !ok {
+			continue
+		}
+		if err := hook(tx); err != nil {
+			return nil, fmt.Errorf("migration %04d_%s failed: %w", m.Version, m.Name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return pending, nil
+}
+
+func applyMigration(tx *sql.Tx, m Migration) error {
+	if _, err := tx.Exec(m.SQL); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(`INSERT INTO schema_version (version, name) VALUES (?, ?)`, m.Version, m.Name)
+	return err
+}
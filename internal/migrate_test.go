@@ -0,0 +1,181 @@
+package internal
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"slices"
+	"testing"
+)
+
+// openV1DB opens a database as the first release of moodgit left it, with
+// the given entries and their json tags.
+func openV1DB(t *testing.T, entries [][3]string) {
+	t.Helper()
+	openEmptyDB(t)
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ensureVersionTable(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(migrations[0].SQL); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO schema_version (version, name) VALUES (1, ?)`, migrations[0].Name); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, e := range entries {
+		_, err := db.Exec(`INSERT INTO entries (intensity, mood, message, tags, created_at, updated_at)
+			VALUES (5, ?, ?, ?, '2025-01-02 03:04:05', '2025-01-02 03:04:05')`, e[0], e[1], e[2])
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func schemaVersion(t *testing.T) int {
+	t.Helper()
+	var version int
+	if err := db.QueryRow(`SELECT MAX(version) FROM schema_version`).Scan(&version); err != nil {
+		t.Fatal(err)
+	}
+	return version
+}
+
+func TestMigrateFromV1(t *testing.T) {
+	openV1DB(t, [][3]string{
+		{"happy", "first", `["work", " work", "", "health"]`},
+		{"sad", "second", `[]`},
+	})
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	applied, err := Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != len(migrations)-1 || applied[0].Version != 2 {
+		t.Fatalf("applied %d migrations starting at %d, want %d starting at 2", len(applied), applied[0].Version, len(migrations)-1)
+	}
+	if got := schemaVersion(t); got != len(migrations) {
+		t.Fatalf("schema version %d, want %d", got, len(migrations))
+	}
+
+	if err := loadMoods(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := QueryEntries(HistoryFilter{Reverse: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if want := []string{"work", "health"}; !slices.Equal(entries[0].Tags, want) {
+		t.Errorf("tags of the first entry are %q, want %q", entries[0].Tags, want)
+	}
+	if entries[1].ParentHash != entries[0].Hash {
+		t.Errorf("second entry isn't chained to the first")
+	}
+
+	checked, problems, err := Fsck()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checked != 2 || len(problems) != 0 {
+		t.Errorf("fsck checked %d entries and found %v, want 2 and none", checked, problems)
+	}
+
+	again, err := Migrate()
+	if err != nil || len(again) != 0 {
+		t.Errorf("second Migrate() = %d migrations, %v, want none", len(again), err)
+	}
+}
+
+func TestMigrateRollsBackOnFailedHook(t *testing.T) {
+	openV1DB(t, [][3]string{{"calm", "", `[]`}})
+
+	failing := errors.New("hook failed")
+	hooks := migrationHooks
+	migrationHooks = map[int]func(tx *sql.Tx) error{
+		2: hooks[2],
+		6: func(tx *sql.Tx) error { return failing },
+	}
+	t.Cleanup(func() { migrationHooks = hooks })
+
+	if _, err := Migrate(); !errors.Is(err, failing) {
+		t.Fatalf("Migrate() = %v, want the hook error", err)
+	}
+
+	if got := schemaVersion(t); got != 1 {
+		t.Errorf("schema version %d after a failed migration, want 1", got)
+	}
+
+	var tables int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name IN ('moods', 'tags')`).Scan(&tables); err != nil {
+		t.Fatal(err)
+	}
+	if tables != 0 {
+		t.Errorf("tables of the failed migrations were kept")
+	}
+}
+
+func TestMigrationStatus(t *testing.T) {
+	t.Run("empty database", func(t *testing.T) {
+		openEmptyDB(t)
+
+		states, err := MigrationStatus()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range states {
+			if s.Applied {
+				t.Errorf("%04d is applied in an empty database", s.Version)
+			}
+		}
+
+		var tables int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master`).Scan(&tables); err != nil {
+			t.Fatal(err)
+		}
+		if tables != 0 {
+			t.Errorf("the status query created %d tables", tables)
+		}
+	})
+
+	t.Run("missing database", func(t *testing.T) {
+		dir := t.TempDir()
+		SetRepo(dir)
+		t.Cleanup(func() { SetRepo("") })
+
+		if err := InspectDB(); err == nil {
+			db.Close()
+			t.Errorf("InspectDB() opened a missing database")
+		}
+		if files, _ := os.ReadDir(dir); len(files) > 0 {
+			t.Errorf("InspectDB() created %s", files[0].Name())
+		}
+	})
+
+	t.Run("newer database", func(t *testing.T) {
+		openTestDB(t)
+
+		if _, err := db.Exec(`INSERT INTO schema_version (version, name) VALUES (999, 'future')`); err != nil {
+			t.Fatal(err)
+		}
+
+		var tooNew *DatabaseTooNewError
+		if _, err := MigrationStatus(); !errors.As(err, &tooNew) {
+			t.Errorf("MigrationStatus() = %v, want a DatabaseTooNewError", err)
+		}
+	})
+}
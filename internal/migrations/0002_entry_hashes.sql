@@ -0,0 +1,14 @@
+ALTER TABLE entries ADD COLUMN hash TEXT NOT NULL DEFAULT '';
+ALTER TABLE entries ADD COLUMN parent_hash TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_entries_hash ON entries(hash);
+
+-- rewriting the hash chain must not look like an edit, so only content
+-- changes bump updated_at
+DROP TRIGGER IF EXISTS update_entries_updated_at;
+CREATE TRIGGER update_entries_updated_at
+    AFTER UPDATE OF intensity, mood, message, tags ON entries
+    FOR EACH ROW
+BEGIN
+    UPDATE entries SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
@@ -0,0 +1,20 @@
+-- every update, delete and restore of an entry saves the state the entry
+-- had right before the operation. the state columns are NULL when the
+-- entry didn't exist at that point (restoring a deleted entry).
+CREATE TABLE IF NOT EXISTS entry_revisions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    entry_id INTEGER NOT NULL,
+    operation TEXT NOT NULL CHECK (operation IN ('update', 'delete', 'restore')),
+    source_revision INTEGER REFERENCES entry_revisions(id),
+    intensity INTEGER,
+    mood TEXT,
+    message TEXT,
+    tags TEXT,
+    entry_created_at DATETIME,
+    entry_updated_at DATETIME,
+    hash TEXT,
+    parent_hash TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_entry_revisions_entry_id ON entry_revisions(entry_id);
@@ -0,0 +1,3 @@
+-- timestamps stay in UTC, tz remembers the IANA zone an entry was logged in
+ALTER TABLE entries ADD COLUMN tz TEXT NOT NULL DEFAULT '';
+ALTER TABLE entry_revisions ADD COLUMN tz TEXT;
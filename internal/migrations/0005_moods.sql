@@ -0,0 +1,60 @@
+-- moods live in their own table so users can define their own. color is a
+-- colour name, a 256-colour index or a #rrggbb hex value, valence and
+-- arousal place the mood on the circumplex model of affect.
+CREATE TABLE IF NOT EXISTS moods (
+    name TEXT PRIMARY KEY CHECK (name <> '' AND name = lower(name)),
+    color TEXT NOT NULL DEFAULT 'white',
+    emoji TEXT NOT NULL DEFAULT '',
+    valence REAL NOT NULL DEFAULT 0 CHECK (valence >= -1 AND valence <= 1),
+    arousal REAL NOT NULL DEFAULT 0 CHECK (arousal >= -1 AND arousal <= 1),
+    builtin INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT OR IGNORE INTO moods (name, color, emoji, valence, arousal, builtin) VALUES
+    ('happy', 'green', '😊', 0.8, 0.5, 1),
+    ('sad', 'blue', '😢', -0.8, -0.5, 1),
+    ('angry', 'red', '😠', -0.7, 0.8, 1),
+    ('anxious', 'yellow', '😰', -0.6, 0.7, 1),
+    ('excited', 'magenta', '🤩', 0.7, 0.9, 1),
+    ('calm', 'cyan', '😌', 0.6, -0.6, 1),
+    ('stressed', 'lightRed', '😫', -0.6, 0.6, 1),
+    ('tired', 'gray', '😴', -0.3, -0.8, 1),
+    ('neutral', 'white', '😐', 0, 0, 1);
+
+-- sqlite can't alter a column constraint, so entries is rebuilt with the
+-- mood CHECK replaced by a foreign key
+CREATE TABLE entries_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    intensity INTEGER NOT NULL CHECK (intensity >= 0 AND intensity <= 10),
+    mood TEXT NOT NULL REFERENCES moods(name),
+    message TEXT NOT NULL DEFAULT '',
+    tags TEXT DEFAULT '[]', -- JSON array stored as text
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    hash TEXT NOT NULL DEFAULT '',
+    parent_hash TEXT NOT NULL DEFAULT '',
+    tz TEXT NOT NULL DEFAULT ''
+);
+
+INSERT INTO entries_new (id, intensity, mood, message, tags, created_at, updated_at, hash, parent_hash, tz)
+    SELECT id, intensity, mood, message, tags, created_at, updated_at, hash, parent_hash, tz FROM entries;
+
+-- keep ids of deleted entries from being reused, the reflog refers to them
+DELETE FROM sqlite_sequence WHERE name = 'entries_new';
+INSERT INTO sqlite_sequence (name, seq) SELECT 'entries_new', seq FROM sqlite_sequence WHERE name = 'entries';
+
+DROP TABLE entries;
+ALTER TABLE entries_new RENAME TO entries;
+
+CREATE INDEX IF NOT EXISTS idx_entries_mood ON entries(mood);
+CREATE INDEX IF NOT EXISTS idx_entries_intensity ON entries(intensity);
+CREATE INDEX IF NOT EXISTS idx_entries_created_at ON entries(created_at);
+CREATE INDEX IF NOT EXISTS idx_entries_hash ON entries(hash);
+
+CREATE TRIGGER update_entries_updated_at
+    AFTER UPDATE OF intensity, mood, message, tags ON entries
+    FOR EACH ROW
+BEGIN
+    UPDATE entries SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
@@ -0,0 +1,37 @@
+-- tags move out of the json column of entries into their own tables, so
+-- they can be matched exactly, counted cheaply and renamed
+CREATE TABLE IF NOT EXISTS tags (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE CHECK (name <> '')
+);
+
+CREATE TABLE IF NOT EXISTS entry_tags (
+    entry_id INTEGER NOT NULL REFERENCES entries(id) ON DELETE CASCADE,
+    tag_id INTEGER NOT NULL REFERENCES tags(id),
+    position INTEGER NOT NULL, -- order the tags were given in
+    PRIMARY KEY (entry_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_entry_tags_tag_id ON entry_tags(tag_id);
+
+INSERT OR IGNORE INTO tags (name)
+    SELECT DISTINCT trim(tag.value) FROM entries, json_each(entries.tags) AS tag
+    WHERE trim(tag.value) <> ''
+    ORDER BY entries.id, tag.key;
+
+INSERT INTO entry_tags (entry_id, tag_id, position)
+    SELECT entries.id, tags.id, MIN(tag.key)
+    FROM entries, json_each(entries.tags) AS tag
+    JOIN tags ON tags.name = trim(tag.value)
+    GROUP BY entries.id, tags.id;
+
+-- the column is named in the updated_at trigger, which has to go first
+DROP TRIGGER IF EXISTS update_entries_updated_at;
+ALTER TABLE entries DROP COLUMN tags;
+
+CREATE TRIGGER update_entries_updated_at
+    AFTER UPDATE OF intensity, mood, message ON entries
+    FOR EACH ROW
+BEGIN
+    UPDATE entries SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
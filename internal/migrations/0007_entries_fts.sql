@@ -0,0 +1,27 @@
+-- full text index over the messages, kept in sync with entries by triggers.
+-- unicode61 with remove_diacritics makes matching case and accent
+-- insensitive, so "cafe" finds "Café".
+CREATE VIRTUAL TABLE IF NOT EXISTS entries_fts USING fts5(
+    message,
+    content = 'entries',
+    content_rowid = 'id',
+    tokenize = 'unicode61 remove_diacritics 2'
+);
+
+INSERT INTO entries_fts (entries_fts) VALUES ('rebuild');
+
+CREATE TRIGGER entries_fts_insert AFTER INSERT ON entries
+BEGIN
+    INSERT INTO entries_fts (rowid, message) VALUES (NEW.id, NEW.message);
+END;
+
+CREATE TRIGGER entries_fts_delete AFTER DELETE ON entries
+BEGIN
+    INSERT INTO entries_fts (entries_fts, rowid, message) VALUES ('delete', OLD.id, OLD.message);
+END;
+
+CREATE TRIGGER entries_fts_update AFTER UPDATE OF message ON entries
+BEGIN
+    INSERT INTO entries_fts (entries_fts, rowid, message) VALUES ('delete', OLD.id, OLD.message);
+    INSERT INTO entries_fts (rowid, message) VALUES (NEW.id, NEW.message);
+END;
@@ -0,0 +1,354 @@
+package internal
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gookit/color"
+	"modernc.org/sqlite"
+)
+
+var (
+	ErrMoodNotFound = errors.New("mood not found")
+	ErrMoodExists   = errors.New("mood already exists")
+)
+
+// MoodInUseError is returned when removing a mood that entries still use.
+type MoodInUseError struct {
+	Mood    Mood
+	Entries int
+}
+
+func (e *MoodInUseError) Error() string {
+	return fmt.Sprintf("mood %q is used by %d entries, edit or remove them first", e.Mood, e.Entries)
+}
+
+// MoodAffect places a mood on the circumplex model of affect: valence goes
+// from -1 (unpleasant) to 1 (pleasant), arousal from -1 (low energy) to 1
+// (high energy).
+type MoodAffect struct {
+	Valence float64 `json:"valence"`
+	Arousal float64 `json:"arousal"`
+}
+
+// MoodDef is a row of the moods table.
+type MoodDef struct {
+	Name  Mood   `json:"name"`
+	Color string `json:"color"`
+	Emoji string `json:"emoji"`
+	MoodAffect
+	Builtin bool `json:"builtin"`
+}
+
+// MoodUsage is a mood with the number of entries using it.
+type MoodUsage struct {
+	MoodDef
+	Entries int `json:"entries"`
+}
+
+var moodName = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,23}$`)
+
+// moods caches the moods table in its order, it is loaded by InitDB and
+// kept up to date by AddMood and RemoveMood.
+var moods []MoodDef
+
+// loadMoods reads the moods table into the cache.
+func loadMoods() error {
+	rows, err := db.Query(`SELECT name, color, emoji, valence, arousal, builtin FROM moods ORDER BY rowid`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var loaded []MoodDef
+	for rows.Next() {
+		var def MoodDef
+		if err := rows.Scan(&def.Name, &def.Color, &def.Emoji, &def.Valence, &def.Arousal, &def.Builtin); err != nil {
+			return err
+		}
+		loaded = append(loaded, def)
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	moods = loaded
+	return nil
+}
+
+// Moods returns all defined moods, the built-in ones first.
+func Moods() []MoodDef {
+	return moods
+}
+
+// MoodNames returns the names of all defined moods.
+func MoodNames() []Mood {
+	names := make([]Mood, len(moods))
+	for i, def := range moods {
+		names[i] = def.Name
+	}
+	return names
+}
+
+// LookupMood returns the definition of the mood, unknown moods are white and
+// neutral.
+func LookupMood(mood Mood) (MoodDef, bool) {
+	for _, def := range moods {
+		if def.Name == mood {
+			return def, true
+		}
+	}
+	return MoodDef{Name: mood, Color: "white"}, false
+}
+
+// Affect returns where the mood sits on the valence/arousal plane.
+func Affect(mood Mood) MoodAffect {
+	def, _ := LookupMood(mood)
+	return def.MoodAffect
+}
+
+// ListMoods returns all moods with the number of entries using each.
+func ListMoods() ([]MoodUsage, error) {
+	rows, err := db.Query(`
+		SELECT moods.name, moods.color, moods.emoji, moods.valence, moods.arousal, moods.builtin, COUNT(entries.id)
+		FROM moods LEFT JOIN entries ON entries.mood = moods.name
+		GROUP BY moods.name ORDER BY moods.rowid`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []MoodUsage{}
+	for rows.Next() {
+		var m MoodUsage
+		if err := rows.Scan(&m.Name, &m.Color, &m.Emoji, &m.Valence, &m.Arousal, &m.Builtin, &m.Entries); err != nil {
+			return nil, err
+		}
+		list = append(list, m)
+	}
+
+	return list, rows.Err()
+}
+
+// AddMood defines a new mood.
+func AddMood(def MoodDef) (MoodDef, error) {
+	def.Name = strings.ToLower(strings.TrimSpace(def.Name))
+	if !moodName.MatchString(def.Name) {
+		return MoodDef{}, fmt.Errorf("invalid mood name %q, use up to 24 lowercase letters, digits, - or _", def.Name)
+	}
+	// the interactive log filters by "all" moods
+	if def.Name == "all" {
+		return MoodDef{}, fmt.Errorf("%q is reserved, pick another mood name", def.Name)
+	}
+
+	if def.Color == "" {
+		def.Color = "white"
+	}
+	if _, err := ParseMoodColor(def.Color); err != nil {
+		return MoodDef{}, err
+	}
+
+	if def.Valence < -1 || def.Valence > 1 {
+		return MoodDef{}, fmt.Errorf("valence must be between -1 and 1")
+	}
+	if def.Arousal < -1 || def.Arousal > 1 {
+		return MoodDef{}, fmt.Errorf("arousal must be between -1 and 1")
+	}
+
+	if _, ok := LookupMood(def.Name); ok {
+		return MoodDef{}, fmt.Errorf("%w: %s", ErrMoodExists, def.Name)
+	}
+
+	def.Builtin = false
+	_, err := db.Exec(`INSERT INTO moods (name, color, emoji, valence, arousal) VALUES (?, ?, ?, ?, ?)`,
+		def.Name, def.Color, def.Emoji, def.Valence, def.Arousal)
+	if err != nil {
+		return MoodDef{}, err
+	}
+
+	return def, loadMoods()
+}
+
+// RemoveMood deletes a mood that no entry uses.
+func RemoveMood(mood Mood) error {
+	if _, ok := LookupMood(mood); !ok {
+		return fmt.Errorf("%w: %s", ErrMoodNotFound, mood)
+	}
+
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM entries WHERE mood = ?`, mood).Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 {
+		return &MoodInUseError{Mood: mood, Entries: count}
+	}
+
+	if _, err := db.Exec(`DELETE FROM moods WHERE name = ?`, mood); err != nil {
+		return err
+	}
+
+	return loadMoods()
+}
+
+// moodColor is an ansi sgr code like "32" or "38;5;208".
+type moodColor string
+
+// colorPrinter is what the charts need from a colour, both gookit colours and
+// mood colours have it.
+type colorPrinter interface {
+	Sprint(a ...any) string
+	Sprintf(format string, a ...any) string
+}
+
+// ParseMoodColor understands colour names (red, lightBlue, gray, ...), 256
+// colour indexes (0-255) and hex values (#ff8800).
+func ParseMoodColor(s string) (moodColor, error) {
+	name := strings.TrimSpace(s)
+
+	switch strings.ToLower(name) {
+	case "gray", "grey":
+		return moodColor(color.Gray.String()), nil
+	}
+
+	for _, names := range []map[string]color.Color{color.FgColors, color.ExFgColors} {
+		for n, c := range names {
+			if strings.EqualFold(n, name) {
+				return moodColor(c.String()), nil
+			}
+		}
+	}
+
+	if n, err := strconv.Atoi(name); err == nil && n >= 0 && n <= 255 {
+		return moodColor(color.C256(uint8(n)).String()), nil
+	}
+
+	if strings.HasPrefix(name, "#") {
+		if rgb := color.HexToRgb(name); len(rgb) == 3 {
+			return moodColor(color.RGB(uint8(rgb[0]), uint8(rgb[1]), uint8(rgb[2])).String()), nil
+		}
+	}
+
+	return "", fmt.Errorf("invalid colour %q, expected a name like red or lightBlue, a number from 0 to 255 or #rrggbb", s)
+}
+
+func (c moodColor) Sprint(a ...any) string {
+	return color.RenderCode(string(c), a...)
+}
+
+func (c moodColor) Sprintf(format string, a ...any) string {
+	return color.RenderCode(string(c), fmt.Sprintf(format, a...))
+}
+
+// with adds display options like bold or italic to the colour.
+func (c moodColor) with(opts ...color.Color) moodColor {
+	code := string(c)
+	for _, opt := range opts {
+		code += ";" + opt.String()
+	}
+	return moodColor(code)
+}
+
+// style returns the colour of the mood, white if it can't be parsed.
+func (def MoodDef) style() moodColor {
+	c, err := ParseMoodColor(def.Color)
+	if err != nil {
+		return moodColor(color.White.String())
+	}
+	return c
+}
+
+// Label is the mood name prefixed with its emoji, if it has one.
+func (def MoodDef) Label() string {
+	if def.Emoji == "" {
+		return def.Name
+	}
+	return def.Emoji + " " + def.Name
+}
+
+func (m MoodUsage) String() string {
+	emoji := m.Emoji
+	if emoji == "" {
+		emoji = "  "
+	}
+
+	line := fmt.Sprintf("%s %s %-10s valence %+.1f arousal %+.1f %5d entries",
+		emoji, m.style().Sprintf("%-12s", m.Name), m.Color, m.Valence, m.Arousal, m.Entries)
+	if m.Builtin {
+		line += color.Gray.Sprint("  built-in")
+	}
+
+	return line
+}
+
+// moodScore scales the valence of the mood by the intensity, giving a single
+// signed axis from -10 (very negative) to 10 (very positive).
+func moodScore(mood Mood, intensity int8) float64 {
+	return round1(Affect(mood).Valence * float64(intensity))
+}
+
+func moodArousal(mood Mood, intensity int8) float64 {
+	return round1(Affect(mood).Arousal * float64(intensity))
+}
+
+func round1(x float64) float64 {
+	return math.Round(x*10) / 10
+}
+
+// Score is the signed mood score of the entry, from -10 to 10.
+func (e *Entry) Score() float64 {
+	return moodScore(e.Mood, e.Intensity)
+}
+
+// Arousal is how energetic the entry was, from -10 to 10.
+func (e *Entry) Arousal() float64 {
+	return moodArousal(e.Mood, e.Intensity)
+}
+
+// describeAffect puts a score and an arousal into words, e.g. "positive,
+// high energy".
+func describeAffect(score, arousal float64) string {
+	valence := "neutral"
+	switch {
+	case score >= 1:
+		valence = "positive"
+	case score <= -1:
+		valence = "negative"
+	}
+
+	energy := "medium energy"
+	switch {
+	case arousal >= 3:
+		energy = "high energy"
+	case arousal <= -3:
+		energy = "low energy"
+	}
+
+	return valence + ", " + energy
+}
+
+func formatScore(score float64) string {
+	return fmt.Sprintf("%+.1f", score)
+}
+
+func init() {
+	// mood_score(mood, intensity) and mood_arousal(mood, intensity) mirror
+	// Entry.Score and Entry.Arousal in sql. they read the mood cache, which
+	// changes with the moods table, so they aren't deterministic
+	sqlite.MustRegisterScalarFunction("mood_score", 2, func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		mood, _ := args[0].(string)
+		intensity, _ := args[1].(int64)
+		return moodScore(mood, int8(intensity)), nil
+	})
+
+	sqlite.MustRegisterScalarFunction("mood_arousal", 2, func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		mood, _ := args[0].(string)
+		intensity, _ := args[1].(int64)
+		return moodArousal(mood, int8(intensity)), nil
+	})
+}
@@ -0,0 +1,112 @@
+package internal
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrEntryNotFound = errors.New("entry not found")
+
+const minHashPrefix = 4
+
+// ResolveRef finds the entry referenced by an id, a (short) hash, HEAD or
+// HEAD~n, where HEAD is the newest entry in the history chain.
+func ResolveRef(ref string) (Entry, error) {
+	ref = strings.TrimSpace(ref)
+
+	if ref == "HEAD" || strings.HasPrefix(ref, "HEAD~") || strings.HasPrefix(ref, "HEAD^") {
+		return resolveHead(ref)
+	}
+
+	if id, err := strconv.Atoi(ref); err == nil {
+		entry, err := getEntryByID(id)
+		if err == nil || !errors.Is(err, ErrEntryNotFound) || len(ref) < minHashPrefix {
+			return entry, wrapNotFound(err, ref)
+		}
+	}
+
+	return resolveHash(ref)
+}
+
+func resolveHead(ref string) (Entry, error) {
+	n := 0
+	rest := strings.TrimPrefix(ref, "HEAD")
+
+	switch {
+	case rest == "":
+	case rest == "^":
+		n = 1
+	case strings.HasPrefix(rest, "~"):
+		if rest == "~" {
+			n = 1
+			break
+		}
+		var err error
+		n, err = strconv.Atoi(rest[1:])
+		if err != nil || n < 0 {
+			return Entry{}, fmt.Errorf("invalid reference %q", ref)
+		}
+	default:
+		return Entry{}, fmt.Errorf("invalid reference %q", ref)
+	}
+
+	row := db.QueryRow(`SELECT `+entryColumns+` FROM entries ORDER BY id DESC LIMIT 1 OFFSET ?`, n)
+	entry, err := scanEntry(row)
+	if err == sql.ErrNoRows {
+		return Entry{}, fmt.Errorf("%w: %s", ErrEntryNotFound, ref)
+	}
+	return entry, err
+}
+
+func resolveHash(ref string) (Entry, error) {
+	prefix := strings.ToLower(ref)
+	if len(prefix) < minHashPrefix || strings.Trim(prefix, "0123456789abcdef") != "" {
+		return Entry{}, fmt.Errorf("%w: %s", ErrEntryNotFound, ref)
+	}
+
+	rows, err := db.Query(`SELECT `+entryColumns+` FROM entries WHERE hash LIKE ? ORDER BY id LIMIT 2`, prefix+"%")
+	if err != nil {
+		return Entry{}, err
+	}
+	defer rows.Close()
+
+	var matches []Entry
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return Entry{}, err
+		}
+		matches = append(matches, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return Entry{}, err
+	}
+
+	switch len(matches) {
+	case 0:
+		return Entry{}, fmt.Errorf("%w: %s", ErrEntryNotFound, ref)
+	case 1:
+		return matches[0], nil
+	default:
+		return Entry{}, fmt.Errorf("short hash %s is ambiguous", ref)
+	}
+}
+
+func getEntryByID(id int) (Entry, error) {
+	row := db.QueryRow(`SELECT `+entryColumns+` FROM entries WHERE id = ?`, id)
+	entry, err := scanEntry(row)
+	if err == sql.ErrNoRows {
+		return Entry{}, ErrEntryNotFound
+	}
+	return entry, err
+}
+
+func wrapNotFound(err error, ref string) error {
+	if errors.Is(err, ErrEntryNotFound) {
+		return fmt.Errorf("%w: %s", ErrEntryNotFound, ref)
+	}
+	return err
+}
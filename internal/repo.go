@@ -0,0 +1,62 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	defaultRepoDir = ".moodgit"
+	dbFileName     = "moodgit.db"
+)
+
+// repoOverride is the directory given with --repo, it wins over everything
+// else.
+var repoOverride string
+
+func SetRepo(dir string) {
+	repoOverride = dir
+}
+
+// RepoDir returns the directory of the moodgit repository, the first of
+//   - the --repo flag
+//   - $MOODGIT_DIR
+//   - ~/.moodgit, if it exists
+//   - $XDG_DATA_HOME/moodgit, if XDG_DATA_HOME is set
+//   - ~/.moodgit
+//
+// an existing ~/.moodgit comes before xdg so setting XDG_DATA_HOME never
+// hides a journal.
+func RepoDir() (string, error) {
+	for _, dir := range []string{repoOverride, os.Getenv("MOODGIT_DIR")} {
+		if dir != "" {
+			return filepath.Abs(dir)
+		}
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	home := filepath.Join(homeDir, defaultRepoDir)
+	if _, err := os.Stat(home); err == nil {
+		return home, nil
+	}
+
+	if xdg := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(xdg) {
+		return filepath.Join(xdg, "moodgit"), nil
+	}
+
+	return home, nil
+}
+
+// DBPath returns the path of the database in the repository.
+func DBPath() (string, error) {
+	dir, err := RepoDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, dbFileName), nil
+}
@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRepoDir(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		repo       string
+		env        string
+		xdg        string
+		homeExists bool
+		want       string
+	}{
+		// paths are in a temporary directory, except absolute ones and those
+		// starting with ./
+		{name: "default", want: "home/.moodgit"},
+		{name: "xdg", xdg: "xdg", want: "xdg/moodgit"},
+		{name: "existing home before xdg", xdg: "xdg", homeExists: true, want: "home/.moodgit"},
+		{name: "relative xdg ignored", xdg: "./xdg", want: "home/.moodgit"},
+		{name: "env", env: "env", xdg: "xdg", homeExists: true, want: "env"},
+		{name: "flag", repo: "flag", env: "env", xdg: "xdg", homeExists: true, want: "flag"},
+		{name: "relative flag", repo: "./journal", want: filepath.Join(cwd, "journal")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			abs := func(path string) string {
+				if path == "" || filepath.IsAbs(path) || strings.HasPrefix(path, "./") {
+					return path
+				}
+				return filepath.Join(dir, path)
+			}
+
+			home := filepath.Join(dir, "home")
+			if err := os.Mkdir(home, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if tt.homeExists {
+				if err := os.Mkdir(filepath.Join(home, defaultRepoDir), 0o755); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			t.Setenv("HOME", home)
+			t.Setenv("MOODGIT_DIR", abs(tt.env))
+			t.Setenv("XDG_DATA_HOME", abs(tt.xdg))
+			SetRepo(abs(tt.repo))
+			t.Cleanup(func() { SetRepo("") })
+
+			got, err := RepoDir()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := abs(tt.want); got != want {
+				t.Errorf("RepoDir() = %s, want %s", got, want)
+			}
+		})
+	}
+}
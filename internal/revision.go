@@ -0,0 +1,244 @@
+package internal
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gookit/color"
+)
+
+const (
+	OpUpdate  = "update"
+	OpDelete  = "delete"
+	OpRestore = "restore"
+)
+
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// Revision is one line of the reflog. Entry holds the state the entry had
+// right before the operation, or nil if it didn't exist back then.
+type Revision struct {
+	ID             int       `json:"id"`
+	EntryID        int       `json:"entry_id"`
+	Operation      string    `json:"operation"`
+	SourceRevision int       `json:"source_revision,omitempty"`
+	Entry          *Entry    `json:"entry"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+const revisionColumns = `id, entry_id, operation, source_revision, intensity, mood, message, tags,
+	entry_created_at, entry_updated_at, hash, parent_hash, tz, created_at`
+
+func scanRevision(row rowScanner) (Revision, error) {
+	var rev Revision
+	var source sql.NullInt64
+	var intensity sql.NullInt16
+	var mood, message, tagsJSON, hash, parentHash, tz sql.NullString
+	var entryCreatedAt, entryUpdatedAt sql.NullTime
+
+	err := row.Scan(&rev.ID, &rev.EntryID, &rev.Operation, &source, &intensity, &mood, &message, &tagsJSON,
+		&entryCreatedAt, &entryUpdatedAt, &hash, &parentHash, &tz, &rev.CreatedAt)
+	if err != nil {
+		return rev, err
+	}
+
+	rev.SourceRevision = int(source.Int64)
+
+	if mood.Valid {
+		rev.Entry = &Entry{
+			ID:         rev.EntryID,
+			Hash:       hash.String,
+			ParentHash: parentHash.String,
+			Intensity:  int8(intensity.Int16),
+			Mood:       mood.String,
+			Message:    message.String,
+			CreatedAt:  entryCreatedAt.Time,
+			UpdatedAt:  entryUpdatedAt.Time,
+			TimeZone:   tz.String,
+		}
+		if err := json.Unmarshal([]byte(tagsJSON.String), &rev.Entry.Tags); err != nil {
+			return rev, err
+		}
+	}
+
+	return rev, nil
+}
+
+// EntryHistory is an entry together with its revisions, newest first. its
+// json is the entry schema with the revisions added as "history".
+type EntryHistory struct {
+	Entry     Entry
+	Revisions []Revision
+}
+
+func (h EntryHistory) MarshalJSON() ([]byte, error) {
+	revisions := h.Revisions
+	if revisions == nil {
+		revisions = []Revision{}
+	}
+
+	return json.Marshal(struct {
+		entryJSON
+		History []Revision `json:"history"`
+	}{h.Entry.schema(), revisions})
+}
+
+func recordRevision(tx *sql.Tx, op string, entryID int, prior *Entry, source int) error {
+	var sourceArg any
+	if source != 0 {
+		sourceArg = source
+	}
+
+	if prior == nil {
+		_, err := tx.Exec(`INSERT INTO entry_revisions (entry_id, operation, source_revision) VALUES (?, ?, ?)`,
+			entryID, op, sourceArg)
+		return err
+	}
+
+	tagsJSON, err := json.Marshal(prior.Tags)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO entry_revisions (entry_id, operation, source_revision, intensity, mood, message, tags,
+			entry_created_at, entry_updated_at, hash, parent_hash, tz)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		entryID, op, sourceArg, prior.Intensity, prior.Mood, prior.Message, string(tagsJSON),
+		prior.CreatedAt.UTC().Format(sqliteTimeLayout), prior.UpdatedAt.UTC().Format(sqliteTimeLayout),
+		prior.Hash, prior.ParentHash, prior.TimeZone)
+	return err
+}
+
+// ListRevisions returns the newest revisions first, optionally only the ones
+// of a single entry (entryID > 0).
+func ListRevisions(entryID int, limit int) ([]Revision, error) {
+	query := `SELECT ` + revisionColumns + ` FROM entry_revisions`
+	var args []any
+
+	if entryID > 0 {
+		query += ` WHERE entry_id = ?`
+		args = append(args, entryID)
+	}
+
+	query += ` ORDER BY id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []Revision
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+
+	return revisions, rows.Err()
+}
+
+// RestoreRevision rolls an entry back to the state saved in the revision,
+// bringing it back if it has been deleted since.
+func RestoreRevision(revisionID int) (Entry, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return Entry{}, err
+	}
+	defer tx.Rollback()
+
+	rev, err := scanRevision(tx.QueryRow(`SELECT `+revisionColumns+` FROM entry_revisions WHERE id = ?`, revisionID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return Entry{}, fmt.Errorf("%w: r%d", ErrRevisionNotFound, revisionID)
+		}
+		return Entry{}, err
+	}
+
+	if rev.Entry == nil {
+		return Entry{}, fmt.Errorf("revision r%d has no earlier state to restore", revisionID)
+	}
+
+	target := rev.Entry
+	if err := target.validate(); err != nil {
+		return Entry{}, fmt.Errorf("can't restore r%d: %w", revisionID, err)
+	}
+
+	current, err := scanEntry(tx.QueryRow(`SELECT `+entryColumns+` FROM entries WHERE id = ?`, rev.EntryID))
+	switch {
+	case err == sql.ErrNoRows:
+		if err := recordRevision(tx, OpRestore, rev.EntryID, nil, rev.ID); err != nil {
+			return Entry{}, err
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO entries (id, intensity, mood, message, created_at, updated_at, tz)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			rev.EntryID, target.Intensity, target.Mood, target.Message,
+			target.CreatedAt.UTC().Format(sqliteTimeLayout), target.UpdatedAt.UTC().Format(sqliteTimeLayout), target.TimeZone)
+		if err != nil {
+			return Entry{}, err
+		}
+
+	case err != nil:
+		return Entry{}, err
+
+	default:
+		if err := recordRevision(tx, OpRestore, rev.EntryID, &current, rev.ID); err != nil {
+			return Entry{}, err
+		}
+
+		_, err = tx.Exec(`
+			UPDATE entries
+			SET intensity = ?, mood = ?, message = ?, created_at = ?, tz = ?
+			WHERE id = ?`,
+			target.Intensity, target.Mood, target.Message,
+			target.CreatedAt.UTC().Format(sqliteTimeLayout), target.TimeZone, rev.EntryID)
+		if err != nil {
+			return Entry{}, err
+		}
+	}
+
+	if err := setEntryTags(tx, rev.EntryID, target.Tags); err != nil {
+		return Entry{}, err
+	}
+
+	if err := rehashFrom(tx, rev.EntryID); err != nil {
+		return Entry{}, err
+	}
+
+	restored, err := scanEntry(tx.QueryRow(`SELECT `+entryColumns+` FROM entries WHERE id = ?`, rev.EntryID))
+	if err != nil {
+		return Entry{}, err
+	}
+
+	return restored, tx.Commit()
+}
+
+func (r *Revision) String() string {
+	parts := []string{
+		color.Yellow.Sprintf("r%d", r.ID),
+		localTime(r.CreatedAt).Format("2006/01/02 15:04"),
+		fmt.Sprintf("%-7s entry %d", r.Operation, r.EntryID),
+	}
+
+	if r.SourceRevision != 0 {
+		parts = append(parts, fmt.Sprintf("from r%d", r.SourceRevision))
+	}
+
+	if r.Entry != nil {
+		parts = append(parts, "was: "+r.Entry.String())
+	} else {
+		parts = append(parts, "was: (deleted)")
+	}
+
+	return strings.Join(parts, " | ")
+}
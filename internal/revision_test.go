@@ -0,0 +1,128 @@
+package internal
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRestoreRevision(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(t *testing.T, id int)
+	}{
+		{"delete", func(t *testing.T, id int) {
+			if err := DeleteEntries([]int{id}); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{"update", func(t *testing.T, id int) {
+			patch := EntryPatch{
+				Mood:      ptr(MoodSad),
+				Intensity: ptr(int8(2)),
+				Message:   ptr("rewritten"),
+				Tags:      ptr([]string{"other"}),
+			}
+			if _, err := UpdateEntry(id, patch); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{"time zone changed", func(t *testing.T, id int) {
+			if _, err := UpdateEntry(id, EntryPatch{Message: ptr("moved")}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := db.Exec(`UPDATE entries SET tz = 'Asia/Tokyo' WHERE id = ?`, id); err != nil {
+				t.Fatal(err)
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			openTestDB(t)
+
+			created := time.Now().Add(-time.Hour).Truncate(time.Second)
+			var original Entry
+			for i, message := range []string{"before", "the one", "after"} {
+				entry := Entry{CreatedAt: created.Add(time.Duration(i) * time.Minute), Mood: MoodCalm, Intensity: 4, Message: message}
+				if i == 1 {
+					entry.TimeZone = "Europe/Berlin"
+					entry.Tags = []string{"home", "family"}
+				}
+
+				added, err := AddEntry(entry)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if i == 1 {
+					original = added
+				}
+			}
+
+			tt.change(t, original.ID)
+
+			revisions, err := ListRevisions(original.ID, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rev := revisions[len(revisions)-1]
+			if rev.Entry == nil || rev.Entry.Message != original.Message {
+				t.Fatalf("the first revision holds %+v, want the original entry", rev.Entry)
+			}
+
+			restored, err := RestoreRevision(rev.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if restored.ID != original.ID || restored.Mood != original.Mood || restored.Intensity != original.Intensity ||
+				restored.Message != original.Message || restored.TimeZone != original.TimeZone ||
+				!restored.CreatedAt.Equal(original.CreatedAt) || !slices.Equal(restored.Tags, original.Tags) {
+				t.Errorf("restored %+v, want %+v", restored, original)
+			}
+			if restored.Hash != original.Hash {
+				t.Errorf("the restored entry hashes to %s, want its original hash %s", restored.Hash, original.Hash)
+			}
+
+			revisions, err = ListRevisions(original.ID, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if latest := revisions[0]; latest.Operation != OpRestore || latest.SourceRevision != rev.ID {
+				t.Errorf("the latest revision is a %s from r%d, want a restore from r%d", latest.Operation, latest.SourceRevision, rev.ID)
+			}
+
+			checked, problems, err := Fsck()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if checked != 3 || len(problems) > 0 {
+				t.Errorf("fsck checked %d entries and found %v, want 3 and none", checked, problems)
+			}
+		})
+	}
+}
+
+func TestRestoreRevisionErrors(t *testing.T) {
+	openTestDB(t)
+
+	if _, err := RestoreRevision(1); err == nil {
+		t.Errorf("restoring a missing revision succeeded")
+	}
+
+	added, err := AddEntry(Entry{Mood: MoodCalm, Intensity: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := DeleteEntries([]int{added.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RestoreRevision(1); err != nil {
+		t.Fatal(err)
+	}
+
+	// the restore of a deleted entry has no earlier state of its own
+	if _, err := RestoreRevision(2); err == nil {
+		t.Errorf("restoring r2 succeeded, want an error")
+	}
+}
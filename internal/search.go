@@ -0,0 +1,142 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gookit/color"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// markers sqlite puts around the matched words of a snippet, replaced by
+// colours when printing
+const (
+	snippetOpen  = "\x02"
+	snippetClose = "\x03"
+)
+
+var snippetHighlight = color.New(color.FgYellow, color.OpBold)
+
+type SearchResult struct {
+	Entry   Entry
+	Snippet string // the matching part of the message, see Highlighted
+}
+
+// SearchEntries runs an fts5 query over the messages of the entries matching
+// the filter, best matches first. the query supports phrases ("a good day"),
+// prefixes (feel*), AND, OR, NOT and grouping with parentheses.
+func SearchEntries(query string, f HistoryFilter) ([]SearchResult, error) {
+	f.Match = ""
+	where, args := f.where()
+
+	sqlQuery := `
+		SELECT ` + entryColumns + `, fts.snippet
+		FROM entries JOIN (
+			SELECT rowid AS fts_id, rank AS fts_rank,
+				snippet(entries_fts, 0, ?, ?, '…', 16) AS snippet
+			FROM entries_fts WHERE entries_fts MATCH ?
+		) AS fts ON fts.fts_id = entries.id
+		WHERE ` + where + `
+		ORDER BY fts.fts_rank, created_at DESC`
+	args = append([]any{snippetOpen, snippetClose, query}, args...)
+
+	if f.Limit > 0 {
+		sqlQuery += ` LIMIT ? OFFSET ?`
+		args = append(args, f.Limit, f.Offset)
+	}
+
+	rows, err := db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, searchError(query, err)
+	}
+	defer rows.Close()
+
+	results := []SearchResult{}
+	for rows.Next() {
+		var result SearchResult
+		var snippet string
+		entry, err := scanEntry(scanWithExtra{rows, &snippet})
+		if err != nil {
+			return nil, err
+		}
+		result.Entry, result.Snippet = entry, snippet
+		results = append(results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, searchError(query, err)
+	}
+	return results, nil
+}
+
+// searchError explains why a search failed. the query is the only part of
+// the statement a user controls, so any sqlite error, from fts5 or from the
+// tokenizer like an unterminated string, comes from its syntax.
+func searchError(query string, err error) error {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) || sqliteErr.Code()&0xff != sqlite3.SQLITE_ERROR {
+		return err
+	}
+
+	// sqlite wraps the reason as "SQL logic error: <reason> (1)"
+	reason := sqliteErr.Error()
+	if _, after, ok := strings.Cut(reason, ": "); ok {
+		reason = after
+	}
+	reason = strings.TrimSuffix(reason, fmt.Sprintf(" (%d)", sqliteErr.Code()))
+
+	return fmt.Errorf("invalid search query %q: %s", query, reason)
+}
+
+// scanWithExtra lets scanEntry read rows that carry extra columns after the
+// entry columns.
+type scanWithExtra struct {
+	row   rowScanner
+	extra any
+}
+
+func (s scanWithExtra) Scan(dest ...any) error {
+	return s.row.Scan(append(dest, s.extra)...)
+}
+
+// Highlighted is the snippet on one line with the matches coloured.
+func (r SearchResult) Highlighted() string {
+	snippet := strings.Join(strings.Fields(r.Snippet), " ")
+
+	var b strings.Builder
+	for {
+		before, rest, ok := strings.Cut(snippet, snippetOpen)
+		b.WriteString(before)
+		if !ok {
+			break
+		}
+
+		match, after, _ := strings.Cut(rest, snippetClose)
+		b.WriteString(snippetHighlight.Sprint(match))
+		snippet = after
+	}
+
+	return b.String()
+}
+
+func (r SearchResult) String() string {
+	e := r.Entry
+	style := e.getIntensityStyle(e.getMoodColor())
+
+	return fmt.Sprintf("%s %s %s %02d/10\n    %s",
+		color.Yellow.Sprint(e.ShortHash()), localTime(e.CreatedAt).Format("2006/01/02 15:04"),
+		style.Sprint(e.Mood), e.Intensity, r.Highlighted())
+}
+
+// typedQuery turns text typed into a search box into an fts5 query: every
+// word is quoted, so operators and punctuation are taken literally, and
+// matched as a prefix, so results show up while typing.
+func typedQuery(text string) string {
+	var terms []string
+	for _, word := range strings.Fields(text) {
+		terms = append(terms, `"`+strings.ReplaceAll(word, `"`, `""`)+`"*`)
+	}
+	return strings.Join(terms, " ")
+}
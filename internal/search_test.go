@@ -0,0 +1,136 @@
+package internal
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTypedQuery(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"", ""},
+		{"   ", ""},
+		{"feel", `"feel"*`},
+		{" good  day ", `"good"* "day"*`},
+		{"NOT work", `"NOT"* "work"*`},
+		{`say "hi"`, `"say"* """hi"""*`},
+		{"c++ (x)", `"c++"* "(x)"*`},
+	}
+
+	for _, tt := range tests {
+		if got := typedQuery(tt.text); got != tt.want {
+			t.Errorf("typedQuery(%q) = %s, want %s", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSearchEntries(t *testing.T) {
+	openTestDB(t)
+
+	for _, e := range []Entry{
+		{Mood: MoodHappy, Intensity: 8, Message: "got a promotion at work"},
+		{Mood: MoodStressed, Intensity: 7, Message: "deadline tomorrow, feeling the pressure"},
+		{Mood: MoodCalm, Intensity: 4, Message: "a good day at the lake", Tags: []string{"homework"}},
+		{Mood: MoodTired, Intensity: 6, Message: "Café au lait all day"},
+	} {
+		if _, err := AddEntry(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  []int // entry ids, in any order
+	}{
+		{"promotion", []int{1}},
+		{"promo*", []int{1}},
+		{`"good day"`, []int{3}},
+		{"day", []int{3, 4}},
+		{"deadline OR lake", []int{2, 3}},
+		{"day NOT lake", []int{4}},
+		{"cafe", []int{4}},
+		{"homework", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			results, err := SearchEntries(tt.query, HistoryFilter{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var ids []int
+			for _, r := range results {
+				ids = append(ids, r.Entry.ID)
+			}
+			slices.Sort(ids)
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("SearchEntries(%q) found %v, want %v", tt.query, ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchEntriesInvalid(t *testing.T) {
+	openTestDB(t)
+
+	if _, err := AddEntry(Entry{Mood: MoodCalm, Intensity: 4, Message: "a good day"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, query := range []string{`"unclosed`, "good AND", "NOT day", "(good", "good)", "x:day", ""} {
+		t.Run(query, func(t *testing.T) {
+			_, err := SearchEntries(query, HistoryFilter{})
+			if err == nil || !strings.HasPrefix(err.Error(), "invalid search query ") || strings.Contains(err.Error(), "SQL logic error") {
+				t.Errorf("SearchEntries(%q) = %v, want an invalid search query error", query, err)
+			}
+		})
+	}
+}
+
+func TestFilterSearch(t *testing.T) {
+	openTestDB(t)
+
+	for _, e := range []Entry{
+		{Mood: MoodCalm, Intensity: 4, Message: "finished my homework"},
+		{Mood: MoodHappy, Intensity: 6, Message: "lunch", Tags: []string{"work"}},
+		{Mood: MoodSad, Intensity: 3, Message: "overworked", Tags: []string{"homework"}},
+	} {
+		if _, err := AddEntry(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		search string
+		want   []int
+	}{
+		// words match as a prefix of message words, tags only as a whole
+		{"work", []int{2}},
+		{"Work", []int{2}},
+		{"home", []int{1}},
+		{"homework", []int{1, 3}},
+		{"over", []int{3}},
+		{"finished home", []int{1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.search, func(t *testing.T) {
+			entries, err := QueryEntries(HistoryFilter{Search: tt.search, Reverse: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var ids []int
+			for _, e := range entries {
+				ids = append(ids, e.ID)
+			}
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("search %q found %v, want %v", tt.search, ids, tt.want)
+			}
+		})
+	}
+}
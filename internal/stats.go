@@ -0,0 +1,311 @@
+package internal
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gookit/color"
+)
+
+type MoodCount struct {
+	Mood          Mood    `json:"mood"`
+	Count         int     `json:"count"`
+	Share         float64 `json:"share"`
+	MeanIntensity float64 `json:"mean_intensity"`
+	Valence       float64 `json:"valence"`
+	Arousal       float64 `json:"arousal"`
+}
+
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+type HourCount struct {
+	Hour  int `json:"hour"`
+	Count int `json:"count"`
+}
+
+type IntensityStats struct {
+	Mean   float64 `json:"mean"`
+	Median float64 `json:"median"`
+	StdDev float64 `json:"stddev"`
+	Min    int     `json:"min"`
+	Max    int     `json:"max"`
+}
+
+// ScoreStats describes the signed mood scores, see Entry.Score.
+type ScoreStats struct {
+	Mean        float64 `json:"mean"`
+	Min         float64 `json:"min"`
+	Max         float64 `json:"max"`
+	MeanArousal float64 `json:"mean_arousal"`
+}
+
+type Streak struct {
+	Days  int    `json:"days"`
+	Start string `json:"start,omitempty"`
+	End   string `json:"end,omitempty"`
+}
+
+type Stats struct {
+	Since         *time.Time     `json:"since"`
+	Until         *time.Time     `json:"until"`
+	Entries       int            `json:"entries"`
+	Days          int            `json:"days"`
+	Intensity     IntensityStats `json:"intensity"`
+	Score         ScoreStats     `json:"score"`
+	Moods         []MoodCount    `json:"moods"`
+	Tags          []TagCount     `json:"tags"`
+	LongestStreak Streak         `json:"longest_streak"`
+	CurrentStreak Streak         `json:"current_streak"`
+	BusiestHours  []HourCount    `json:"busiest_hours"`
+}
+
+const (
+	statsTopTags  = 10
+	statsTopHours = 3
+)
+
+// GetStats summarises the entries matching the filter, all aggregation is
+// done by sqlite and days and hours are taken in the display time zone.
+func GetStats(f HistoryFilter) (*Stats, error) {
+	where, args := f.where()
+	stats := &Stats{Moods: []MoodCount{}, Tags: []TagCount{}, BusiestHours: []HourCount{}}
+
+	if !f.Since.IsZero() {
+		since := localTime(f.Since)
+		stats.Since = &since
+	}
+	if !f.Until.IsZero() {
+		until := localTime(f.Until)
+		stats.Until = &until
+	}
+
+	var mean, stddev sql.NullFloat64
+	var minIntensity, maxIntensity sql.NullInt64
+	var score, minScore, maxScore, arousal sql.NullFloat64
+	err := db.QueryRow(`
+		SELECT COUNT(*), COUNT(DISTINCT local_date(created_at)),
+			AVG(intensity), sqrt(AVG(intensity * intensity) - AVG(intensity) * AVG(intensity)),
+			MIN(intensity), MAX(intensity),
+			AVG(mood_score(mood, intensity)), MIN(mood_score(mood, intensity)), MAX(mood_score(mood, intensity)),
+			AVG(mood_arousal(mood, intensity))
+		FROM entries WHERE `+where, args...).
+		Scan(&stats.Entries, &stats.Days, &mean, &stddev, &minIntensity, &maxIntensity,
+			&score, &minScore, &maxScore, &arousal)
+	if err != nil {
+		return nil, err
+	}
+
+	if stats.Entries == 0 {
+		return stats, nil
+	}
+
+	stats.Intensity = IntensityStats{
+		Mean:   mean.Float64,
+		StdDev: stddev.Float64,
+		Min:    int(minIntensity.Int64),
+		Max:    int(maxIntensity.Int64),
+	}
+
+	stats.Score = ScoreStats{
+		Mean:        score.Float64,
+		Min:         minScore.Float64,
+		Max:         maxScore.Float64,
+		MeanArousal: arousal.Float64,
+	}
+
+	// the median is the middle row, or the mean of the two middle rows
+	err = db.QueryRow(`
+		SELECT AVG(intensity) FROM (
+			SELECT intensity FROM entries WHERE `+where+`
+			ORDER BY intensity LIMIT 2 - (? % 2) OFFSET (? - 1) / 2
+		)`, append(args, stats.Entries, stats.Entries)...).Scan(&stats.Intensity.Median)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := stats.loadMoods(where, args); err != nil {
+		return nil, err
+	}
+
+	if err := stats.loadTags(where, args); err != nil {
+		return nil, err
+	}
+
+	if err := stats.loadStreaks(where, args); err != nil {
+		return nil, err
+	}
+
+	if err := stats.loadHours(where, args); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+func (s *Stats) loadMoods(where string, args []any) error {
+	rows, err := db.Query(`
+		SELECT mood, COUNT(*), AVG(intensity)
+		FROM entries WHERE `+where+`
+		GROUP BY mood ORDER BY COUNT(*) DESC, mood`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var mc MoodCount
+		if err := rows.Scan(&mc.Mood, &mc.Count, &mc.MeanIntensity); err != nil {
+			return err
+		}
+		mc.Share = float64(mc.Count) / float64(s.Entries)
+		affect := Affect(mc.Mood)
+		mc.Valence, mc.Arousal = affect.Valence, affect.Arousal
+		s.Moods = append(s.Moods, mc)
+	}
+
+	return rows.Err()
+}
+
+func (s *Stats) loadTags(where string, args []any) error {
+	rows, err := db.Query(`
+		SELECT tags.name, COUNT(*)
+		FROM entries
+		JOIN entry_tags ON entry_tags.entry_id = entries.id
+		JOIN tags ON tags.id = entry_tags.tag_id
+		WHERE `+where+`
+		GROUP BY tags.id ORDER BY COUNT(*) DESC, tags.name LIMIT ?`, append(args, statsTopTags)...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tc TagCount
+		if err := rows.Scan(&tc.Tag, &tc.Count); err != nil {
+			return err
+		}
+		s.Tags = append(s.Tags, tc)
+	}
+
+	return rows.Err()
+}
+
+// loadStreaks finds runs of consecutive logging days (gaps and islands): the
+// day number minus the row number is constant within a run.
+func (s *Stats) loadStreaks(where string, args []any) error {
+	rows, err := db.Query(`
+		WITH days AS (
+			SELECT DISTINCT local_date(created_at) AS day FROM entries WHERE `+where+`
+		), runs AS (
+			SELECT day, julianday(day) - ROW_NUMBER() OVER (ORDER BY day) AS run FROM days
+		)
+		SELECT COUNT(*), MIN(day), MAX(day) FROM runs GROUP BY run ORDER BY MAX(day)`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var last Streak
+	for rows.Next() {
+		var streak Streak
+		if err := rows.Scan(&streak.Days, &streak.Start, &streak.End); err != nil {
+			return err
+		}
+		if streak.Days >= s.LongestStreak.Days {
+			s.LongestStreak = streak
+		}
+		last = streak
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// a streak is still current if it ends today or yesterday
+	today := Now()
+	yesterday := today.AddDate(0, 0, -1)
+	if last.End == today.Format("2006-01-02") || last.End == yesterday.Format("2006-01-02") {
+		s.CurrentStreak = last
+	}
+
+	return nil
+}
+
+func (s *Stats) loadHours(where string, args []any) error {
+	rows, err := db.Query(`
+		SELECT local_hour(created_at) AS hour, COUNT(*)
+		FROM entries WHERE `+where+`
+		GROUP BY hour ORDER BY COUNT(*) DESC, hour LIMIT ?`, append(args, statsTopHours)...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var hc HourCount
+		if err := rows.Scan(&hc.Hour, &hc.Count); err != nil {
+			return err
+		}
+		s.BusiestHours = append(s.BusiestHours, hc)
+	}
+
+	return rows.Err()
+}
+
+func (s *Stats) String() string {
+	var b strings.Builder
+
+	if s.Entries == 0 {
+		return "no entries in this range"
+	}
+
+	fmt.Fprintf(&b, "entries    %d on %d days\n", s.Entries, s.Days)
+	fmt.Fprintf(&b, "intensity  mean %.1f, median %.1f, stddev %.1f (min %d, max %d)\n",
+		s.Intensity.Mean, s.Intensity.Median, s.Intensity.StdDev, s.Intensity.Min, s.Intensity.Max)
+	fmt.Fprintf(&b, "score      mean %s, %s (min %s, max %s)\n",
+		formatScore(s.Score.Mean), describeAffect(s.Score.Mean, s.Score.MeanArousal), formatScore(s.Score.Min), formatScore(s.Score.Max))
+	fmt.Fprintf(&b, "streaks    longest %s, current %s\n", s.LongestStreak.describe(), s.CurrentStreak.describe())
+
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "%-12s %6s %6s %6s\n", "mood", "count", "share", "mean")
+	for _, mc := range s.Moods {
+		entry := Entry{Mood: mc.Mood}
+		moodColor := entry.getMoodColor()
+		bar := moodColor.Sprint(strings.Repeat("█", max(1, int(mc.Share*20+0.5))))
+		fmt.Fprintf(&b, "%s %6d %5.0f%% %6.1f  %s\n", moodColor.Sprintf("%-12s", mc.Mood), mc.Count, mc.Share*100, mc.MeanIntensity, bar)
+	}
+
+	if len(s.Tags) > 0 {
+		b.WriteString("\n")
+		fmt.Fprintf(&b, "%-12s %6s\n", "top tags", "count")
+		for _, tc := range s.Tags {
+			fmt.Fprintf(&b, "%-12s %6d\n", tc.Tag, tc.Count)
+		}
+	}
+
+	if len(s.BusiestHours) > 0 {
+		b.WriteString("\n")
+		fmt.Fprintf(&b, "%-13s%6s\n", "busiest hours", "count")
+		for _, hc := range s.BusiestHours {
+			fmt.Fprintf(&b, "%s %6d\n", color.Cyan.Sprintf("%02d:00-%02d:00 ", hc.Hour, (hc.Hour+1)%24), hc.Count)
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func (s Streak) describe() string {
+	switch s.Days {
+	case 0:
+		return "none"
+	case 1:
+		return fmt.Sprintf("1 day (%s)", s.Start)
+	default:
+		return fmt.Sprintf("%d days (%s to %s)", s.Days, s.Start, s.End)
+	}
+}
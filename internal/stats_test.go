@@ -0,0 +1,206 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetStats(t *testing.T) {
+	type fixture struct {
+		at        time.Time
+		intensity int8
+	}
+
+	utc := func(d, hour, minute int) time.Time {
+		return time.Date(2025, 10, d, hour, minute, 0, 0, time.UTC)
+	}
+	daily := func(days ...int) []fixture {
+		var entries []fixture
+		for _, d := range days {
+			entries = append(entries, fixture{utc(d, 12, 0), 5})
+		}
+		return entries
+	}
+
+	// midnight of a day relative to today in the test zone, for current
+	// streaks
+	today := time.Now().In(testZone)
+	daysAgo := func(n int) fixture {
+		d := today.AddDate(0, 0, -n)
+		return fixture{time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, testZone), 5}
+	}
+	localDay := func(n int) string {
+		return today.AddDate(0, 0, -n).Format(time.DateOnly)
+	}
+
+	tests := []struct {
+		name    string
+		zone    *time.Location
+		entries []fixture
+		days    int
+		median  float64
+		longest Streak
+		current Streak
+	}{
+		{
+			name:    "odd median",
+			entries: []fixture{{utc(1, 9, 0), 2}, {utc(1, 10, 0), 9}, {utc(1, 11, 0), 4}},
+			days:    1,
+			median:  4,
+			longest: Streak{1, "2025-10-01", "2025-10-01"},
+		},
+		{
+			name:    "even median",
+			entries: []fixture{{utc(1, 9, 0), 2}, {utc(1, 10, 0), 9}, {utc(1, 11, 0), 4}, {utc(2, 9, 0), 7}},
+			days:    2,
+			median:  5.5,
+			longest: Streak{2, "2025-10-01", "2025-10-02"},
+		},
+		{
+			name:    "single entry",
+			entries: []fixture{{utc(1, 9, 0), 7}},
+			days:    1,
+			median:  7,
+			longest: Streak{1, "2025-10-01", "2025-10-01"},
+		},
+		{
+			// 23:30 and 00:30 in utc+2
+			name:    "both sides of local midnight",
+			zone:    testZone,
+			entries: []fixture{{utc(1, 21, 30), 3}, {utc(1, 22, 30), 6}},
+			days:    2,
+			median:  4.5,
+			longest: Streak{2, "2025-10-01", "2025-10-02"},
+		},
+		{
+			name:    "same utc day",
+			entries: []fixture{{utc(1, 21, 30), 3}, {utc(1, 22, 30), 6}},
+			days:    1,
+			median:  4.5,
+			longest: Streak{1, "2025-10-01", "2025-10-01"},
+		},
+		{
+			name:    "longest run",
+			entries: daily(1, 2, 3, 5, 7, 8),
+			days:    6,
+			median:  5,
+			longest: Streak{3, "2025-10-01", "2025-10-03"},
+		},
+		{
+			name:    "latest of equal runs",
+			entries: daily(1, 2, 4, 5),
+			days:    4,
+			median:  5,
+			longest: Streak{2, "2025-10-04", "2025-10-05"},
+		},
+		{
+			name:    "run across months",
+			entries: append(daily(30), fixture{time.Date(2025, 10, 31, 12, 0, 0, 0, time.UTC), 5}, fixture{time.Date(2025, 11, 1, 12, 0, 0, 0, time.UTC), 5}),
+			days:    3,
+			median:  5,
+			longest: Streak{3, "2025-10-30", "2025-11-01"},
+		},
+		{
+			name:    "current streak until today",
+			zone:    testZone,
+			entries: []fixture{daysAgo(5), daysAgo(2), daysAgo(1), daysAgo(0)},
+			days:    4,
+			median:  5,
+			longest: Streak{3, localDay(2), localDay(0)},
+			current: Streak{3, localDay(2), localDay(0)},
+		},
+		{
+			name:    "current streak until yesterday",
+			zone:    testZone,
+			entries: []fixture{daysAgo(3), daysAgo(2), daysAgo(1)},
+			days:    3,
+			median:  5,
+			longest: Streak{3, localDay(3), localDay(1)},
+			current: Streak{3, localDay(3), localDay(1)},
+		},
+		{
+			name:    "broken streak",
+			zone:    testZone,
+			entries: []fixture{daysAgo(4), daysAgo(3), daysAgo(2)},
+			days:    3,
+			median:  5,
+			longest: Streak{3, localDay(4), localDay(2)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			openTestDB(t)
+
+			location := displayLocation
+			t.Cleanup(func() { displayLocation = location })
+			displayLocation = time.UTC
+			if tt.zone != nil {
+				displayLocation = tt.zone
+			}
+
+			for _, e := range tt.entries {
+				if _, err := AddEntry(Entry{CreatedAt: e.at, Mood: MoodCalm, Intensity: e.intensity}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			stats, err := GetStats(HistoryFilter{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if stats.Entries != len(tt.entries) || stats.Days != tt.days {
+				t.Errorf("counted %d entries on %d days, want %d on %d", stats.Entries, stats.Days, len(tt.entries), tt.days)
+			}
+			if stats.Intensity.Median != tt.median {
+				t.Errorf("median intensity %v, want %v", stats.Intensity.Median, tt.median)
+			}
+			if stats.LongestStreak != tt.longest {
+				t.Errorf("longest streak %+v, want %+v", stats.LongestStreak, tt.longest)
+			}
+			if stats.CurrentStreak != tt.current {
+				t.Errorf("current streak %+v, want %+v", stats.CurrentStreak, tt.current)
+			}
+		})
+	}
+}
+
+func TestGetStatsFiltered(t *testing.T) {
+	openTestDB(t)
+	inUTC(t)
+	addFilterFixture(t)
+
+	tests := []struct {
+		name    string
+		filter  HistoryFilter
+		entries int
+		median  float64
+		hours   []HourCount
+	}{
+		{"all", HistoryFilter{}, 5, 6, []HourCount{{8, 1}, {9, 1}, {12, 1}}},
+		{"work", HistoryFilter{Tags: []string{"work"}}, 3, 7, []HourCount{{8, 1}, {9, 1}, {22, 1}}},
+		{"happy", HistoryFilter{Moods: []Mood{MoodHappy}}, 2, 7, []HourCount{{8, 1}, {9, 1}}},
+		{"none", HistoryFilter{Moods: []Mood{MoodTired}}, 0, 0, []HourCount{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats, err := GetStats(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stats.Entries != tt.entries || stats.Intensity.Median != tt.median {
+				t.Errorf("%d entries with median %v, want %d with median %v", stats.Entries, stats.Intensity.Median, tt.entries, tt.median)
+			}
+			if len(stats.BusiestHours) != len(tt.hours) {
+				t.Fatalf("busiest hours %v, want %v", stats.BusiestHours, tt.hours)
+			}
+			for i := range tt.hours {
+				if stats.BusiestHours[i] != tt.hours[i] {
+					t.Errorf("busiest hours %v, want %v", stats.BusiestHours, tt.hours)
+				}
+			}
+		})
+	}
+}
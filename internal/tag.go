@@ -0,0 +1,246 @@
+package internal
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrTagNotFound = errors.New("tag not found")
+	ErrTagExists   = errors.New("tag already exists")
+)
+
+// normalizeTags trims the tags and drops blank and repeated ones.
+func normalizeTags(tags []string) ([]string, error) {
+	normalized := []string{}
+	seen := map[string]bool{}
+
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if strings.Contains(tag, ",") {
+			return nil, fmt.Errorf("invalid tag %q, tags can't contain commas", tag)
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+
+	return normalized, nil
+}
+
+// setEntryTags replaces the tags of an entry, keeping their order.
+func setEntryTags(tx *sql.Tx, entryID int, tags []string) error {
+	if _, err := tx.Exec(`DELETE FROM entry_tags WHERE entry_id = ?`, entryID); err != nil {
+		return err
+	}
+
+	for i, tag := range tags {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO tags (name) VALUES (?)`, tag); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(`
+			INSERT OR IGNORE INTO entry_tags (entry_id, tag_id, position)
+			SELECT ?, id, ? FROM tags WHERE name = ?`, entryID, i, tag)
+		if err != nil {
+			return err
+		}
+	}
+
+	return pruneTags(tx)
+}
+
+// pruneTags removes tags no entry carries any more.
+func pruneTags(tx *sql.Tx) error {
+	_, err := tx.Exec(`DELETE FROM tags WHERE id NOT IN (SELECT tag_id FROM entry_tags)`)
+	return err
+}
+
+// TagCounts returns every tag in use with the number of entries carrying it,
+// the most used first.
+func TagCounts() ([]TagCount, error) {
+	rows, err := db.Query(`
+		SELECT tags.name, COUNT(*)
+		FROM tags JOIN entry_tags ON entry_tags.tag_id = tags.id
+		GROUP BY tags.id ORDER BY COUNT(*) DESC, tags.name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []TagCount{}
+	for rows.Next() {
+		var tc TagCount
+		if err := rows.Scan(&tc.Tag, &tc.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, tc)
+	}
+
+	return counts, rows.Err()
+}
+
+func tagExists(name string) (bool, error) {
+	var exists bool
+	err := db.QueryRow(`SELECT EXISTS (SELECT 1 FROM tags WHERE name = ?)`, name).Scan(&exists)
+	return exists, err
+}
+
+func validTagName(name string) (string, error) {
+	tags, err := normalizeTags([]string{name})
+	if err != nil {
+		return "", err
+	}
+	if len(tags) == 0 {
+		return "", fmt.Errorf("tag name can't be empty")
+	}
+	return tags[0], nil
+}
+
+// RenameTag renames a tag on every entry carrying it and returns how many
+// entries changed. renaming onto an existing tag is a merge, see MergeTags.
+func RenameTag(from, to string) (int, error) {
+	to, err := validTagName(to)
+	if err != nil {
+		return 0, err
+	}
+
+	if exists, err := tagExists(to); err != nil {
+		return 0, err
+	} else if exists {
+		return 0, fmt.Errorf("%w: %s, use tag merge to combine tags", ErrTagExists, to)
+	}
+
+	return MergeTags([]string{from}, to)
+}
+
+// MergeTags replaces every one of the given tags by into and returns how
+// many entries changed.
+func MergeTags(tags []string, into string) (int, error) {
+	into, err := validTagName(into)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := requireTags(tags); err != nil {
+		return 0, err
+	}
+
+	merged := map[string]bool{}
+	for _, tag := range tags {
+		merged[tag] = true
+	}
+
+	return retagEntries(tags, func(tag string) string {
+		if merged[tag] {
+			return into
+		}
+		return tag
+	})
+}
+
+// RemoveTag takes a tag off every entry and returns how many entries changed.
+func RemoveTag(tag string) (int, error) {
+	if err := requireTags([]string{tag}); err != nil {
+		return 0, err
+	}
+
+	return retagEntries([]string{tag}, func(t string) string {
+		if t == tag {
+			return ""
+		}
+		return t
+	})
+}
+
+func requireTags(tags []string) error {
+	for _, tag := range tags {
+		exists, err := tagExists(tag)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("%w: %s", ErrTagNotFound, tag)
+		}
+	}
+	return nil
+}
+
+// retagEntries rewrites the tags of every entry carrying one of the given
+// tags. each entry keeps its old state in the reflog and the hash chain is
+// rewritten, just like with UpdateEntry. replace returns "" to drop a tag.
+func retagEntries(tags []string, replace func(tag string) string) (int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	args := make([]any, len(tags))
+	for i, tag := range tags {
+		args[i] = tag
+	}
+
+	rows, err := tx.Query(`
+		SELECT `+entryColumns+` FROM entries WHERE EXISTS (
+			SELECT 1 FROM entry_tags JOIN tags ON tags.id = entry_tags.tag_id
+			WHERE entry_tags.entry_id = entries.id AND tags.name IN (`+placeholders(len(tags))+`)
+		) ORDER BY id`, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	var entries []Entry
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		entries = append(entries, entry)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if len(entries) == 0 {
+		return 0, nil
+	}
+
+	now := time.Now().UTC().Format(sqliteTimeLayout)
+	for _, entry := range entries {
+		if err := recordRevision(tx, OpUpdate, entry.ID, &entry, 0); err != nil {
+			return 0, err
+		}
+
+		var retagged []string
+		for _, tag := range entry.Tags {
+			retagged = append(retagged, replace(tag))
+		}
+
+		retagged, err := normalizeTags(retagged)
+		if err != nil {
+			return 0, err
+		}
+
+		if err := setEntryTags(tx, entry.ID, retagged); err != nil {
+			return 0, err
+		}
+
+		if _, err := tx.Exec(`UPDATE entries SET updated_at = ? WHERE id = ?`, now, entry.ID); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := rehashFrom(tx, entries[0].ID); err != nil {
+		return 0, err
+	}
+
+	return len(entries), tx.Commit()
+}
@@ -0,0 +1,179 @@
+package internal
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		tags    []string
+		want    []string
+		invalid bool
+	}{
+		{name: "none", tags: nil, want: []string{}},
+		{name: "kept in order", tags: []string{"work", "health"}, want: []string{"work", "health"}},
+		{name: "trimmed", tags: []string{" work ", "\thealth"}, want: []string{"work", "health"}},
+		{name: "blank dropped", tags: []string{"", " ", "work"}, want: []string{"work"}},
+		{name: "duplicates dropped", tags: []string{"work", " work", "health", "work"}, want: []string{"work", "health"}},
+		{name: "case kept", tags: []string{"Work", "work"}, want: []string{"Work", "work"}},
+		{name: "spaces inside kept", tags: []string{"deep work"}, want: []string{"deep work"}},
+		{name: "comma", tags: []string{"a,b"}, invalid: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeTags(tt.tags)
+			if tt.invalid {
+				if err == nil {
+					t.Errorf("normalizeTags(%q) = %q, want an error", tt.tags, got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) || got == nil {
+				t.Errorf("normalizeTags(%q) = %#v, want %#v", tt.tags, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetagEntries(t *testing.T) {
+	fixture := [][]string{
+		{"work", "health"},
+		{"job", "work"},
+		{"health"},
+		nil,
+	}
+
+	tests := []struct {
+		name    string
+		run     func() (int, error)
+		err     error
+		changed int
+		want    [][]string // the tags of the fixture entries afterwards
+	}{
+		{
+			name:    "rename",
+			run:     func() (int, error) { return RenameTag("work", " career ") },
+			changed: 2,
+			want:    [][]string{{"career", "health"}, {"job", "career"}, {"health"}, {}},
+		},
+		{
+			name: "rename onto an existing tag",
+			run:  func() (int, error) { return RenameTag("work", "health") },
+			err:  ErrTagExists,
+		},
+		{
+			name: "rename a missing tag",
+			run:  func() (int, error) { return RenameTag("play", "fun") },
+			err:  ErrTagNotFound,
+		},
+		{
+			name:    "merge into one of the tags",
+			run:     func() (int, error) { return MergeTags([]string{"job", "work"}, "work") },
+			changed: 2,
+			want:    [][]string{{"work", "health"}, {"work"}, {"health"}, {}},
+		},
+		{
+			name:    "merge into a new tag",
+			run:     func() (int, error) { return MergeTags([]string{"health", "work"}, "life") },
+			changed: 3,
+			want:    [][]string{{"life"}, {"job", "life"}, {"life"}, {}},
+		},
+		{
+			name: "merge a missing tag",
+			run:  func() (int, error) { return MergeTags([]string{"job", "play"}, "work") },
+			err:  ErrTagNotFound,
+		},
+		{
+			name:    "remove",
+			run:     func() (int, error) { return RemoveTag("health") },
+			changed: 2,
+			want:    [][]string{{"work"}, {"job", "work"}, {}, {}},
+		},
+		{
+			name: "remove a missing tag",
+			run:  func() (int, error) { return RemoveTag("Health") },
+			err:  ErrTagNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			openTestDB(t)
+
+			for _, tags := range fixture {
+				if _, err := AddEntry(Entry{Mood: MoodCalm, Intensity: 4, Tags: tags}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want := tt.want
+			changed, err := tt.run()
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("got %v, want %v", err, tt.err)
+				}
+				want = fixture
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if changed != tt.changed {
+				t.Errorf("changed %d entries, want %d", changed, tt.changed)
+			}
+
+			entries, err := QueryEntries(HistoryFilter{Reverse: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			inUse := map[string]bool{}
+			for i, e := range entries {
+				if !slices.Equal(e.Tags, want[i]) {
+					t.Errorf("entry %d has tags %q, want %q", e.ID, e.Tags, want[i])
+				}
+				for _, tag := range e.Tags {
+					inUse[tag] = true
+				}
+			}
+
+			// tags no entry carries any more are pruned
+			counts, err := TagCounts()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(counts) != len(inUse) {
+				t.Errorf("%d tags are left, want %d: %v", len(counts), len(inUse), counts)
+			}
+			var stored int
+			if err := db.QueryRow(`SELECT COUNT(*) FROM tags`).Scan(&stored); err != nil {
+				t.Fatal(err)
+			}
+			if stored != len(inUse) {
+				t.Errorf("the tags table holds %d tags, want %d", stored, len(inUse))
+			}
+
+			revisions, err := ListRevisions(0, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(revisions) != tt.changed {
+				t.Errorf("recorded %d revisions, want one per changed entry", len(revisions))
+			}
+
+			checked, problems, err := Fsck()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if checked != len(fixture) || len(problems) > 0 {
+				t.Errorf("fsck checked %d entries and found %v, want %d and none", checked, problems, len(fixture))
+			}
+		})
+	}
+}
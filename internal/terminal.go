@@ -0,0 +1,30 @@
+package internal
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/charmbracelet/x/term"
+)
+
+const defaultTerminalWidth = 80
+
+// TerminalWidth is the width of stdout, falling back to $COLUMNS and then 80
+// columns when output is piped.
+func TerminalWidth() int {
+	if width, _, err := term.GetSize(os.Stdout.Fd()); err == nil && width > 0 {
+		return width
+	}
+
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width
+	}
+
+	return defaultTerminalWidth
+}
+
+// IsInteractive reports whether both stdin and stdout are terminals, so a
+// form can be shown instead of asking for flags.
+func IsInteractive() bool {
+	return term.IsTerminal(os.Stdin.Fd()) && term.IsTerminal(os.Stdout.Fd())
+}
@@ -0,0 +1,103 @@
+package internal
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"modernc.org/sqlite"
+)
+
+// timestamps are stored in UTC and converted to this location for display
+// and for everything that buckets entries by day or hour.
+var displayLocation = time.Local
+
+func SetTimeZone(name string) error {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return fmt.Errorf("failed to load time zone: %w", err)
+	}
+
+	displayLocation = loc
+	return nil
+}
+
+func Location() *time.Location {
+	return displayLocation
+}
+
+// Now is the current time in the display location.
+func Now() time.Time {
+	return time.Now().In(displayLocation)
+}
+
+func localTime(t time.Time) time.Time {
+	return t.In(displayLocation)
+}
+
+// zoneName returns the IANA name of the display location, or "" if it can't
+// be determined (e.g. no zoneinfo link on the system).
+func zoneName() string {
+	if name := displayLocation.String(); name != "Local" {
+		return name
+	}
+	return localZoneName()
+}
+
+// localZoneName returns the IANA name of the machine's time zone, the one new
+// entries are logged in. --tz only changes how times are displayed.
+func localZoneName() string {
+	if tz := os.Getenv("TZ"); tz != "" {
+		return strings.TrimPrefix(tz, ":")
+	}
+
+	target, err := filepath.EvalSymlinks("/etc/localtime")
+	if err != nil {
+		return ""
+	}
+
+	if _, name, ok := strings.Cut(target, "zoneinfo/"); ok {
+		return name
+	}
+
+	return ""
+}
+
+// sqlTime reads a timestamp handed to a sql function, sqlite passes the raw
+// column text.
+func sqlTime(v driver.Value) (time.Time, bool) {
+	switch v := v.(type) {
+	case time.Time:
+		return v, true
+	case string:
+		t, err := time.ParseInLocation(sqliteTimeLayout, v, time.UTC)
+		return t, err == nil
+	case []byte:
+		t, err := time.ParseInLocation(sqliteTimeLayout, string(v), time.UTC)
+		return t, err == nil
+	}
+	return time.Time{}, false
+}
+
+func init() {
+	// local_date(ts) and local_hour(ts) bucket utc timestamps in the display
+	// location, sqlite's own 'localtime' modifier can't honour --tz.
+	sqlite.MustRegisterDeterministicScalarFunction("local_date", 1, func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		t, ok := sqlTime(args[0])
+		if !ok {
+			return nil, nil
+		}
+		return localTime(t).Format("2006-01-02"), nil
+	})
+
+	sqlite.MustRegisterDeterministicScalarFunction("local_hour", 1, func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		t, ok := sqlTime(args[0])
+		if !ok {
+			return nil, nil
+		}
+		return int64(localTime(t).Hour()), nil
+	})
+}
@@ -0,0 +1,140 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	MinIntensity = 0
+	MaxIntensity = 10
+
+	// the farthest a typo may be from a mood to still be suggested
+	maxSuggestionDistance = 2
+)
+
+// InvalidMoodError is returned for a mood that isn't defined and isn't the
+// prefix of exactly one defined mood.
+type InvalidMoodError struct {
+	Input string
+	// Suggestion is the closest defined mood, if one is close enough
+	Suggestion Mood
+	// Candidates are the moods an ambiguous prefix matches
+	Candidates []Mood
+}
+
+func (e *InvalidMoodError) Error() string {
+	switch {
+	case len(e.Candidates) > 0:
+		return fmt.Sprintf("ambiguous mood %q, could be %s", e.Input, strings.Join(e.Candidates, ", "))
+	case e.Suggestion != "":
+		return fmt.Sprintf("unknown mood %q, did you mean %s?", e.Input, e.Suggestion)
+	default:
+		return fmt.Sprintf("unknown mood %q, see moodgit mood list", e.Input)
+	}
+}
+
+type InvalidIntensityError struct {
+	Intensity int
+}
+
+func (e *InvalidIntensityError) Error() string {
+	return fmt.Sprintf("intensity %d is out of range, expected %d to %d", e.Intensity, MinIntensity, MaxIntensity)
+}
+
+// ResolveMood returns the defined mood the input names, either exactly or as
+// an unambiguous prefix ("exc" for excited). case and surrounding spaces are
+// ignored.
+func ResolveMood(input string) (Mood, error) {
+	name := strings.ToLower(strings.TrimSpace(input))
+	names := MoodNames()
+
+	var candidates []Mood
+	for _, mood := range names {
+		if mood == name {
+			return mood, nil
+		}
+		if name != "" && strings.HasPrefix(mood, name) {
+			candidates = append(candidates, mood)
+		}
+	}
+
+	if len(candidates) == 1 {
+		return candidates[0], nil
+	}
+
+	if len(candidates) > 1 {
+		sort.Strings(candidates)
+		return "", &InvalidMoodError{Input: input, Candidates: candidates}
+	}
+
+	return "", &InvalidMoodError{Input: input, Suggestion: closestMood(name, names)}
+}
+
+// closestMood returns the mood with the smallest edit distance to name, or
+// nothing if none is within maxSuggestionDistance.
+func closestMood(name string, moods []Mood) Mood {
+	best, bestDistance := "", maxSuggestionDistance+1
+	for _, mood := range moods {
+		if d := levenshtein(name, mood); d < bestDistance {
+			best, bestDistance = mood, d
+		}
+	}
+	return best
+}
+
+// levenshtein counts the single character insertions, deletions and
+// substitutions needed to turn a into b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+func ValidateIntensity(intensity int8) error {
+	if intensity < MinIntensity || intensity > MaxIntensity {
+		return &InvalidIntensityError{Intensity: int(intensity)}
+	}
+	return nil
+}
+
+// validate checks the entry before it is written, replaces a mood prefix
+// with the full mood name and cleans up the tags.
+func (e *Entry) validate() error {
+	if err := ValidateIntensity(e.Intensity); err != nil {
+		return err
+	}
+
+	mood, err := ResolveMood(e.Mood)
+	if err != nil {
+		return err
+	}
+	e.Mood = mood
+
+	tags, err := normalizeTags(e.Tags)
+	if err != nil {
+		return err
+	}
+	e.Tags = tags
+
+	return nil
+}
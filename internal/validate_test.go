@@ -0,0 +1,99 @@
+package internal
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"", "sad", 3},
+		{"sad", "", 3},
+		{"happy", "happy", 0},
+		{"hapy", "happy", 1},
+		{"hpapy", "happy", 2},
+		{"anxius", "anxious", 1},
+		{"calm", "clam", 2},
+		{"kitten", "sitting", 3},
+		{"müde", "mude", 1},
+	}
+
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := levenshtein(tt.b, tt.a); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestResolveMood(t *testing.T) {
+	openTestDB(t)
+
+	tests := []struct {
+		input      string
+		want       Mood
+		suggestion Mood
+		candidates []Mood
+	}{
+		{input: "happy", want: MoodHappy},
+		{input: " Calm ", want: MoodCalm},
+		{input: "exc", want: MoodExcited},
+		{input: "ne", want: MoodNeutral},
+		{input: "a", candidates: []Mood{MoodAngry, MoodAnxious}},
+		{input: "s", candidates: []Mood{MoodSad, MoodStressed}},
+		{input: "hapy", suggestion: MoodHappy},
+		{input: "anxius", suggestion: MoodAnxious},
+		{input: "tierd", suggestion: MoodTired},
+		{input: "furious"},
+		{input: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ResolveMood(tt.input)
+			if tt.want != "" {
+				if err != nil || got != tt.want {
+					t.Errorf("ResolveMood(%q) = %q, %v, want %q", tt.input, got, err, tt.want)
+				}
+				return
+			}
+
+			var invalid *InvalidMoodError
+			if !errors.As(err, &invalid) {
+				t.Fatalf("ResolveMood(%q) = %q, %v, want an InvalidMoodError", tt.input, got, err)
+			}
+			if invalid.Suggestion != tt.suggestion {
+				t.Errorf("suggestion for %q is %q, want %q", tt.input, invalid.Suggestion, tt.suggestion)
+			}
+			if !slices.Equal(invalid.Candidates, tt.candidates) {
+				t.Errorf("candidates for %q are %q, want %q", tt.input, invalid.Candidates, tt.candidates)
+			}
+		})
+	}
+}
+
+func TestValidateIntensity(t *testing.T) {
+	tests := []struct {
+		intensity int8
+		valid     bool
+	}{
+		{-1, false},
+		{MinIntensity, true},
+		{5, true},
+		{MaxIntensity, true},
+		{11, false},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateIntensity(tt.intensity); (err == nil) != tt.valid {
+			t.Errorf("ValidateIntensity(%d) = %v, want valid %v", tt.intensity, err, tt.valid)
+		}
+	}
+}